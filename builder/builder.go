@@ -1,19 +1,45 @@
 package builder
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"gopkg.in/yaml.v3"
+	"gowizard/builder/fsys"
+	"gowizard/builder/model/chatfw"
+	"gowizard/builder/model/httpfw"
 	"gowizard/builder/model/system"
 	"gowizard/consts"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 )
 
 type Builder struct {
 	ProjectName string     `yaml:"project_name"`
 	Layers      []LayerDTO `yaml:"layers"`
-	Unsafe      bool       `yaml:"unsafe"`
-	Path        string     `yaml:"path"`
+	// Unsafe overwrites the files edited since the last generation, see Resolve
+	Unsafe bool   `yaml:"unsafe"`
+	Path   string `yaml:"path"`
+	// ConfigFormat is the format of the generated config file, json (default), yaml or toml
+	ConfigFormat string `yaml:"config_format"`
+	// Config are extra typed fields of the generated config
+	Config []system.ConfigField `yaml:"config"`
+	// Environments are the config profiles, the config values by field name for each environment name
+	Environments map[string]map[string]string `yaml:"environments"`
+	// Demo generates in-memory repositories in place of the databases
+	Demo bool `yaml:"-"`
+	// DryRun generates into memory with no go tools run, see Plan
+	DryRun bool `yaml:"-"`
+	// FS is where the files are written, the disk by default
+	FS fsys.FS `yaml:"-" json:"-"`
+	// Resolve settles the files edited since the last generation, they are skipped when it is nil
+	// unless the spec is unsafe
+	Resolve func(path string) (Resolution, error) `yaml:"-" json:"-"`
+	// Report tells what happened to the files edited since the last generation
+	Report []string `yaml:"-" json:"-"`
 
 	Models []*system.Model `yaml:"models"`
 
@@ -21,8 +47,9 @@ type Builder struct {
 }
 
 type LayerDTO struct {
-	Layer string `yaml:"layer"`
-	Tag   string `yaml:"tag"`
+	Layer  string `yaml:"layer"`
+	Tag    string `yaml:"tag"`
+	Poller string `yaml:"poller"`
 }
 
 func (b *Builder) setDefaultsIfEmpty() string {
@@ -33,13 +60,20 @@ func (b *Builder) setDefaultsIfEmpty() string {
 	if b.Path == "" {
 		b.Path = "gowizard"
 	}
-	if !b.Unsafe {
-		b.Path = filepath.Join("magic", b.Path)
-	}
 	if b.Path[len(b.Path)-1] != '/' {
 		b.Path = b.Path + "/"
 	}
 
+	if b.ConfigFormat == "" {
+		b.ConfigFormat = consts.ConfigFormatJSON
+	}
+
+	for i := range b.Config {
+		if b.Config[i].Type == "" {
+			b.Config[i].Type = system.ConfigString
+		}
+	}
+
 	if b.Layers == nil {
 		b.Layers = []LayerDTO{
 			{
@@ -56,12 +90,68 @@ func (b *Builder) setDefaultsIfEmpty() string {
 		}
 	}
 
+	if b.Demo {
+		b.useMemoryRepositories()
+	}
+
+	if b.FS == nil {
+		b.FS = fsys.NewOS()
+		if b.DryRun {
+			b.FS = fsys.NewMemory()
+		}
+	}
+
 	return b.ProjectName
 }
 
+// useMemoryRepositories swaps the database tags for the memory one, so the project runs with no database
+func (b *Builder) useMemoryRepositories() {
+	for i := range b.Layers {
+		switch b.Layers[i].Tag {
+		case consts.RepoLayerType, consts.MySQLLayerType, consts.SQLiteLayerType, consts.MongoLayerType:
+			b.Layers[i].Tag = consts.MemoryLayerType
+		}
+	}
+}
+
 func (b *Builder) CodeGenerate() error {
 	b.setDefaultsIfEmpty()
 
+	switch b.ConfigFormat {
+	case consts.ConfigFormatJSON, consts.ConfigFormatYAML, consts.ConfigFormatTOML:
+	default:
+		return fmt.Errorf("unknown config format %s, use json, yaml or toml", b.ConfigFormat)
+	}
+
+	var locked *lockFS
+	if !b.DryRun {
+		err := b.checkTools()
+		if err != nil {
+			return err
+		}
+
+		spec, err := b.specHash()
+		if err != nil {
+			return err
+		}
+
+		locked, err = newLockFS(b.FS, b.Path, spec, b.resolver())
+		if err != nil {
+			return fmt.Errorf("unable to read lock: %w", err)
+		}
+
+		unchanged, err := locked.unchanged()
+		if err != nil {
+			return fmt.Errorf("unable to check lock: %w", err)
+		}
+		if unchanged {
+			b.Report = []string{fmt.Sprintf("the spec is unchanged since the last generation, nothing is written, "+
+				"remove %s to generate again", consts.LockFile)}
+			return nil
+		}
+		b.FS = locked
+	}
+
 	err := b.initStructure()
 	if err != nil {
 		return fmt.Errorf("unable to generate directories: %w", err)
@@ -77,6 +167,23 @@ func (b *Builder) CodeGenerate() error {
 		return fmt.Errorf("unable to generate layers: %w", err)
 	}
 
+	// the go tools work on the disk
+	if b.DryRun {
+		return nil
+	}
+
+	err = locked.removeStale()
+	if err != nil {
+		return fmt.Errorf("unable to remove stale files: %w", err)
+	}
+
+	if b.LayerController.HaveGRPC {
+		err = b.protocGenerate()
+		if err != nil {
+			return err
+		}
+	}
+
 	err = b.goModTidy()
 	if err != nil {
 		return err
@@ -87,16 +194,44 @@ func (b *Builder) CodeGenerate() error {
 		return err
 	}
 
-	if b.LayerController.HaveHTTP {
+	if b.LayerController.HaveSwagger {
 		err = b.swaggerGenerate()
 		if err != nil {
 			return err
 		}
 	}
 
+	err = locked.save()
+	if err != nil {
+		return fmt.Errorf("unable to write lock: %w", err)
+	}
+	b.Report = locked.report
+
 	return nil
 }
 
+// specHash is the hash of the spec with its defaults
+func (b *Builder) specHash() (string, error) {
+	spec, err := yaml.Marshal(b)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash spec: %w", err)
+	}
+	sum := sha256.Sum256(spec)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// resolver settles the conflicts with Resolve, an unsafe spec overwrites them
+func (b *Builder) resolver() func(path string) (Resolution, error) {
+	if b.Unsafe {
+		return func(string) (Resolution, error) {
+			return ResolveOverwrite, nil
+		}
+	}
+
+	return b.Resolve
+}
+
 const templateMain = `package main
 
 import (
@@ -109,7 +244,7 @@ func main() {
 `
 
 func (b *Builder) initStructure() error {
-	if _, err := createIfNoExist(b.Path); err != nil {
+	if _, err := createIfNoExist(b.FS, b.Path); err != nil {
 		return fmt.Errorf("unable to create main directory: %w", err)
 	}
 
@@ -118,7 +253,15 @@ func (b *Builder) initStructure() error {
 	var lt = make(map[string]struct{}, 10)
 	for i, layer := range b.LayerController.Layers {
 		lt[layer.Type] = struct{}{}
-		path, err := createIfNoExist(filepath.Join(b.Path, layer.Name))
+		if fw := httpfw.Select(layer.Type); fw != nil {
+			b.LayerController.HaveHTTP = true
+			b.LayerController.HaveSwagger = b.LayerController.HaveSwagger || fw.Swagger()
+		}
+		if layer.Type == consts.GraphQLLayerType {
+			b.LayerController.HaveHTTP = true
+		}
+
+		path, err := createIfNoExist(b.FS, filepath.Join(b.Path, layer.Name))
 		if err != nil {
 			return fmt.Errorf("unable to create %s directory: %w", layer.Name, err)
 		}
@@ -126,29 +269,72 @@ func (b *Builder) initStructure() error {
 		b.LayerController.Layers[i].Path = path
 	}
 
-	_, err := createIfNoExist(filepath.Join(b.Path, consts.DefaultModelsFolder))
+	_, err := createIfNoExist(b.FS, filepath.Join(b.Path, consts.DefaultModelsFolder))
 	if err != nil {
 		return fmt.Errorf("unable to create models directory: %w", err)
 	}
 
-	_, err = createIfNoExist(filepath.Join(b.Path, consts.DefaultConfigFolder))
+	_, err = createIfNoExist(b.FS, filepath.Join(b.Path, consts.DefaultConfigFolder))
 	if err != nil {
 		return fmt.Errorf("unable to create config directory: %w", err)
 	}
 
-	if _, ok := lt[consts.HTTPLayerType]; ok {
-		b.LayerController.HaveHTTP = true
-		_, err = createIfNoExist(filepath.Join(b.Path, consts.DefaultRouterFolder))
+	if b.LayerController.HaveHTTP {
+		_, err = createIfNoExist(b.FS, filepath.Join(b.Path, consts.DefaultRouterFolder))
 		if err != nil {
 			return fmt.Errorf("unable to create router directory: %w", err)
 		}
 	}
 
-	if _, ok := lt[consts.TelebotLayerType]; ok {
-		b.LayerController.HaveTelebot = true
-		_, err = createIfNoExist(filepath.Join(b.Path, consts.DefaultTelerouterFolder))
+	if _, ok := lt[consts.GRPCLayerType]; ok {
+		b.LayerController.HaveGRPC = true
+		_, err = createIfNoExist(b.FS, filepath.Join(b.Path, consts.DefaultProtoFolder))
 		if err != nil {
-			return fmt.Errorf("unable to create telerouter directory: %w", err)
+			return fmt.Errorf("unable to create proto directory: %w", err)
+		}
+
+		_, err = createIfNoExist(b.FS, filepath.Join(b.Path, consts.DefaultGRPCServerFolder))
+		if err != nil {
+			return fmt.Errorf("unable to create grpc server directory: %w", err)
+		}
+	}
+
+	for _, layer := range b.LayerController.Layers {
+		platform := chatfw.Select(layer.Type)
+		if platform == nil {
+			continue
+		}
+
+		b.LayerController.HaveChat = true
+		_, err = createIfNoExist(b.FS, filepath.Join(b.Path, platform.Folder()))
+		if err != nil {
+			return fmt.Errorf("unable to create %s directory: %w", platform.Folder(), err)
+		}
+
+		_, err = createIfNoExist(b.FS, filepath.Join(b.Path, consts.DefaultChatFolder))
+		if err != nil {
+			return fmt.Errorf("unable to create chat directory: %w", err)
+		}
+	}
+
+	if _, ok := lt[consts.ConsumerLayerType]; ok {
+		b.LayerController.HaveConsumer = true
+		_, err = createIfNoExist(b.FS, filepath.Join(b.Path, consts.DefaultBrokerFolder))
+		if err != nil {
+			return fmt.Errorf("unable to create broker directory: %w", err)
+		}
+
+		_, err = createIfNoExist(b.FS, filepath.Join(b.Path, consts.DefaultSubscriberFolder))
+		if err != nil {
+			return fmt.Errorf("unable to create subscriber directory: %w", err)
+		}
+	}
+
+	if _, ok := lt[consts.CLILayerType]; ok {
+		b.LayerController.HaveCLI = true
+		_, err = createIfNoExist(b.FS, filepath.Join(b.Path, consts.DefaultCommandsFolder))
+		if err != nil {
+			return fmt.Errorf("unable to create commands directory: %w", err)
 		}
 	}
 
@@ -156,16 +342,28 @@ func (b *Builder) initStructure() error {
 		b.LayerController.HavePostgres = true
 	}
 
+	if _, ok := lt[consts.MySQLLayerType]; ok {
+		b.LayerController.HaveMySQL = true
+	}
+
+	if _, ok := lt[consts.MongoLayerType]; ok {
+		b.LayerController.HaveMongo = true
+	}
+
+	if _, ok := lt[consts.RedisLayerType]; ok {
+		b.LayerController.HaveRedis = true
+	}
+
 	return nil
 }
 
-func createIfNoExist(fp string) (string, error) {
+func createIfNoExist(fs fsys.FS, fp string) (string, error) {
 	if fp[len(fp)-1] != '/' {
 		fp = fp + "/"
 	}
 
 	if !checkIfExist(fp) {
-		err := os.MkdirAll(fp, os.ModePerm)
+		err := fs.MkdirAll(fp)
 		if err != nil {
 			return "", fmt.Errorf("unable to create directory %s: %w", fp, err)
 		}
@@ -184,30 +382,37 @@ func checkIfExist(fp string) bool {
 }
 
 func (b *Builder) mainGenerate() error {
-	f, err := os.Create(filepath.Join(b.Path, "main.go"))
-	if err != nil {
-		return fmt.Errorf("unable to create file: %w", err)
-	}
+	// a project generated before keeps its main.go until the layers replace it
+	if _, err := b.FS.ReadFile(filepath.Join(b.Path, "main.go")); err != nil {
+		f, err := b.FS.Create(filepath.Join(b.Path, "main.go"))
+		if err != nil {
+			return fmt.Errorf("unable to create file: %w", err)
+		}
 
-	_, err = f.Write([]byte(templateMain))
-	if err != nil {
-		return fmt.Errorf("unable to write to file: %w", err)
-	}
+		_, err = f.Write([]byte(templateMain))
+		if err != nil {
+			return fmt.Errorf("unable to write to file: %w", err)
+		}
 
-	err = f.Close()
-	if err != nil {
-		return fmt.Errorf("unable to close file: %w", err)
+		err = f.Close()
+		if err != nil {
+			return fmt.Errorf("unable to close file: %w", err)
+		}
 	}
 
-	// If there is no go.mod file or go.sum file, there will be an expected error, so we ignore it
-	_ = os.Remove(filepath.Join(b.Path, "go.mod"))
-	_ = os.Remove(filepath.Join(b.Path, "go.sum"))
+	if b.DryRun {
+		return nil
+	}
 
+	// the go.mod of a project generated before keeps its requirements, only the module is renamed
 	cmd := exec.Command("go", "mod", "init", b.ProjectName)
+	if _, err := os.Stat(filepath.Join(b.Path, "go.mod")); err == nil {
+		cmd = exec.Command("go", "mod", "edit", "-module", b.ProjectName)
+	}
 	cmd.Dir = b.Path
-	err = cmd.Run()
+	err := cmd.Run()
 	if err != nil {
-		return fmt.Errorf("unable to run go mod init: %w", err)
+		return fmt.Errorf("unable to run %s: %w", strings.Join(cmd.Args, " "), err)
 	}
 
 	return nil
@@ -235,6 +440,37 @@ func (b *Builder) gofmt() error {
 	return nil
 }
 
+// protocTools are the binaries compiling the proto files of a grpc project
+var protocTools = []string{"protoc", "protoc-gen-go", "protoc-gen-go-grpc"}
+
+// checkTools reports a tool of the generation missing from PATH before anything is written
+func (b *Builder) checkTools() error {
+	if !slices.ContainsFunc(b.Layers, func(layer LayerDTO) bool { return layer.Tag == consts.GRPCLayerType }) {
+		return nil
+	}
+
+	for _, tool := range protocTools {
+		_, err := exec.LookPath(tool)
+		if err != nil {
+			return fmt.Errorf("%s is required to generate grpc, install it in PATH: %w", tool, err)
+		}
+	}
+
+	return nil
+}
+
+// protocGenerate compiles the proto files next to them, so the generated project builds without protoc
+func (b *Builder) protocGenerate() error {
+	cmd := exec.Command("protoc", b.LayerController.ProtocArgs()...)
+	cmd.Dir = b.Path
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("unable to run protoc: %w", err)
+	}
+
+	return nil
+}
+
 // swaggerGenerate runs swag init --parseDependency --parseInternal --parseDepth 1
 func (b *Builder) swaggerGenerate() error {
 	cmd := exec.Command("swag", "init", "--parseDependency", "--parseInternal", "--parseDepth", "1")
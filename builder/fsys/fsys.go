@@ -0,0 +1,120 @@
+package fsys
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// FS is the filesystem the project is generated into
+type FS interface {
+	// Create truncates the file at the path or creates it
+	Create(path string) (File, error)
+	MkdirAll(path string) error
+	ReadFile(path string) ([]byte, error)
+	Remove(path string) error
+}
+
+type File interface {
+	io.Writer
+	io.StringWriter
+	io.Closer
+}
+
+// OS writes to the disk
+type OS struct{}
+
+var _ FS = &OS{}
+
+func NewOS() *OS {
+	return &OS{}
+}
+
+func (*OS) Create(path string) (File, error) {
+	return os.Create(path)
+}
+
+func (*OS) MkdirAll(path string) error {
+	return os.MkdirAll(path, os.ModePerm)
+}
+
+func (*OS) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func (*OS) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// Memory keeps the written files in memory over the disk, a file is stored when it is closed
+type Memory struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+var _ FS = &Memory{}
+
+func NewMemory() *Memory {
+	return &Memory{files: make(map[string][]byte)}
+}
+
+func (m *Memory) Create(path string) (File, error) {
+	return &memoryFile{path: filepath.Clean(path), fs: m}, nil
+}
+
+// MkdirAll does nothing, the directories are implied by the file paths
+func (m *Memory) MkdirAll(string) error {
+	return nil
+}
+
+func (m *Memory) ReadFile(path string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return os.ReadFile(path)
+	}
+
+	return bytes.Clone(data), nil
+}
+
+// Remove drops the stored file, the disk is left as it is
+func (m *Memory) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.files, filepath.Clean(path))
+	return nil
+}
+
+// Paths returns the paths of the stored files in order
+func (m *Memory) Paths() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	paths := make([]string, 0, len(m.files))
+	for path := range m.files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	return paths
+}
+
+type memoryFile struct {
+	bytes.Buffer
+	path string
+	fs   *Memory
+}
+
+func (f *memoryFile) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	f.fs.files[f.path] = bytes.Clone(f.Bytes())
+	return nil
+}
@@ -0,0 +1,259 @@
+package gen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"go/parser"
+	"go/token"
+	"gowizard/builder/fsys"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A protected region is a generated body between marker comments, the begin marker holds the hash
+// of the generated body, so a body edited by hand is told apart and carried over on regeneration:
+//
+//	// gowizard:begin CreateUser 1f2e3d4c
+//	panic("implement me")
+//	// gowizard:end CreateUser
+const (
+	regionBegin = "// gowizard:begin "
+	regionEnd   = "// gowizard:end "
+
+	orphanComment = "gowizard: the regions below were edited by hand but are no longer generated,\n" +
+		"they are carried over until their methods come back"
+)
+
+type region struct {
+	name string
+	hash string
+	body string
+}
+
+// edited reports whether the body no longer matches the generated one
+func (r region) edited() bool {
+	return regionHash(r.body) != r.hash
+}
+
+// Region wraps the generated body in the markers of a protected region
+func Region(name, body string) string {
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+
+	return regionBegin + name + " " + regionHash(body) + "\n" + body + regionEnd + name + "\n"
+}
+
+// regionHash ignores the white space, so formatting the generated code keeps the body untouched
+func regionHash(body string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(body), " ")))
+	return hex.EncodeToString(sum[:4])
+}
+
+// parseRegions reads the protected regions of the file in order
+func parseRegions(src []byte) []region {
+	var (
+		regions []region
+		current *region
+		body    strings.Builder
+	)
+	for _, line := range strings.SplitAfter(string(src), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case current == nil && strings.HasPrefix(trimmed, regionBegin):
+			fields := strings.Fields(strings.TrimPrefix(trimmed, regionBegin))
+			if len(fields) != 2 {
+				continue
+			}
+			current = &region{name: fields[0], hash: fields[1]}
+			body.Reset()
+		case current != nil && trimmed == regionEnd+current.name:
+			current.body = body.String()
+			regions = append(regions, *current)
+			current = nil
+		case current != nil:
+			body.WriteString(line)
+		}
+	}
+
+	return regions
+}
+
+// HasEditedRegions reports whether a protected region of the file was edited by hand
+func HasEditedRegions(src []byte) bool {
+	return slices.ContainsFunc(parseRegions(src), region.edited)
+}
+
+// StripRegions drops the bodies of the protected regions and keeps their markers, the begin markers
+// hold the hashes of the generated bodies, so the result still tells apart what was generated
+func StripRegions(src []byte) []byte {
+	var (
+		out  bytes.Buffer
+		skip string
+	)
+	for _, line := range strings.SplitAfter(string(src), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case skip != "" && trimmed != regionEnd+skip:
+			continue
+		case skip != "":
+			skip = ""
+		case strings.HasPrefix(trimmed, regionBegin):
+			if fields := strings.Fields(strings.TrimPrefix(trimmed, regionBegin)); len(fields) == 2 {
+				skip = fields[0]
+			}
+		}
+		out.WriteString(line)
+	}
+
+	return out.Bytes()
+}
+
+// mergeRegions carries the regions edited by hand of the current file into the generated one and
+// returns the regions carried, the edited regions with no place in the generated file are kept in
+// a comment at its end
+func mergeRegions(generated []byte, current []region) ([]byte, []region) {
+	edited := make(map[string]region, len(current))
+	for _, r := range current {
+		if _, ok := edited[r.name]; !ok && r.edited() {
+			edited[r.name] = r
+		}
+	}
+	if len(edited) == 0 {
+		return generated, nil
+	}
+
+	var (
+		out     bytes.Buffer
+		skip    string
+		carried []region
+	)
+	for _, line := range strings.SplitAfter(string(generated), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if skip != "" {
+			if trimmed != regionEnd+skip {
+				continue
+			}
+			skip = ""
+		}
+
+		out.WriteString(line)
+		if !strings.HasPrefix(trimmed, regionBegin) {
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(trimmed, regionBegin))
+		if len(fields) != 2 {
+			continue
+		}
+		if r, ok := edited[fields[0]]; ok {
+			out.WriteString(r.body)
+			carried = append(carried, r)
+			delete(edited, r.name)
+			skip = r.name
+		}
+	}
+
+	// the orphans keep the order of the current file
+	var orphans []region
+	for _, r := range current {
+		if _, ok := edited[r.name]; ok {
+			orphans = append(orphans, r)
+			delete(edited, r.name)
+		}
+	}
+	if len(orphans) == 0 {
+		return out.Bytes(), carried
+	}
+
+	out.WriteString("\n/*\n" + orphanComment + "\n\n")
+	for _, r := range orphans {
+		out.WriteString(regionBegin + r.name + " " + r.hash + "\n" + r.body + regionEnd + r.name + "\n")
+	}
+	out.WriteString("*/\n")
+
+	return out.Bytes(), carried
+}
+
+// carryImports adds the imports of the current go file used by the carried regions and missing
+// from the generated file, a body edited by hand may use packages the generator knows nothing of
+func carryImports(generated, current []byte, carried []region) []byte {
+	fset := token.NewFileSet()
+	currentFile, err := parser.ParseFile(fset, "", current, parser.ImportsOnly)
+	if err != nil {
+		return generated
+	}
+	generatedFile, err := parser.ParseFile(fset, "", generated, parser.ImportsOnly)
+	if err != nil {
+		return generated
+	}
+
+	imported := make(map[string]bool, len(generatedFile.Imports))
+	for _, spec := range generatedFile.Imports {
+		imported[spec.Path.Value] = true
+	}
+
+	var missing []string
+	for _, spec := range currentFile.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || imported[spec.Path.Value] {
+			continue
+		}
+
+		name := path.Base(importPath)
+		line := spec.Path.Value
+		if spec.Name != nil {
+			name = spec.Name.Name
+			line = name + " " + line
+		}
+
+		used := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\.`)
+		for _, r := range carried {
+			if used.MatchString(r.body) {
+				missing = append(missing, line)
+				break
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return generated
+	}
+
+	// the generator writes one import block, the carried imports join it
+	lines := []byte(strings.Join(missing, "\n") + "\n")
+	if i := bytes.Index(generated, []byte("import (\n")); i != -1 {
+		at := i + len("import (\n")
+		return slices.Concat(generated[:at], lines, generated[at:])
+	}
+
+	at := fset.Position(generatedFile.Name.End()).Offset
+	return slices.Concat(generated[:at], []byte("\n\nimport (\n"), lines, []byte(")\n"), generated[at:])
+}
+
+// regionFile buffers the generated file, the protected regions of the replaced file are merged in on Close
+type regionFile struct {
+	bytes.Buffer
+	path    string
+	file    fsys.File
+	current []byte
+}
+
+func (f *regionFile) Close() error {
+	merged, carried := mergeRegions(f.Bytes(), parseRegions(f.current))
+	if len(carried) > 0 && filepath.Ext(f.path) == ".go" {
+		merged = carryImports(merged, f.current, carried)
+	}
+
+	_, err := f.file.Write(merged)
+	if err != nil {
+		_ = f.file.Close()
+		return err
+	}
+
+	return f.file.Close()
+}
@@ -0,0 +1,257 @@
+package gen
+
+import (
+	"gowizard/builder/fsys"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseRegions(t *testing.T) {
+	src := "func (u *user) Create() {\n" +
+		Region("Create", "return nil\n") +
+		"}\n\nfunc (u *user) Read() {\n" +
+		Region("Read", "panic(\"implement me\")") +
+		"}\n"
+
+	regions := parseRegions([]byte(src))
+	want := []region{
+		{name: "Create", hash: regionHash("return nil\n"), body: "return nil\n"},
+		{name: "Read", hash: regionHash("panic(\"implement me\")\n"), body: "panic(\"implement me\")\n"},
+	}
+	if !slices.Equal(regions, want) {
+		t.Fatalf("got %v, want %v", regions, want)
+	}
+	for _, r := range regions {
+		if r.edited() {
+			t.Fatalf("region %s is not edited", r.name)
+		}
+	}
+}
+
+func TestRegionEdited(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		edited bool
+	}{
+		{name: "generated", body: "return nil\n", edited: false},
+		{name: "formatted", body: "\treturn   nil\n\n", edited: false},
+		{name: "edited", body: "return errors.New(\"no\")\n", edited: true},
+		{name: "emptied", body: "", edited: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := region{name: "Create", hash: regionHash("return nil\n"), body: tt.body}
+			if r.edited() != tt.edited {
+				t.Fatalf("got edited %v, want %v", r.edited(), tt.edited)
+			}
+		})
+	}
+}
+
+func TestParseRegionsUnclosed(t *testing.T) {
+	src := regionBegin + "Create 00000000\nreturn nil\n"
+	if regions := parseRegions([]byte(src)); len(regions) != 0 {
+		t.Fatalf("got %v, an unclosed region is not a region", regions)
+	}
+}
+
+func TestStripRegions(t *testing.T) {
+	src := "package service\n\nfunc f() {\n" + Region("F", "return\n") + "}\n"
+	edited := strings.Replace(src, "return\n"+regionEnd, "println()\nreturn\n"+regionEnd, 1)
+
+	want := "package service\n\nfunc f() {\n" + regionBegin + "F " + regionHash("return\n") + "\n" + regionEnd + "F\n}\n"
+	if got := string(StripRegions([]byte(src))); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	if string(StripRegions([]byte(edited))) != string(StripRegions([]byte(src))) {
+		t.Fatal("an edited region body changes the stripped file")
+	}
+}
+
+func TestHasEditedRegions(t *testing.T) {
+	src := Region("F", "return\n")
+	if HasEditedRegions([]byte(src)) {
+		t.Fatal("the generated region is not edited")
+	}
+	if !HasEditedRegions([]byte(strings.Replace(src, "return", "return // done", 1))) {
+		t.Fatal("the region is edited")
+	}
+}
+
+func TestMergeRegions(t *testing.T) {
+	generated := "func a() {\n" + Region("A", "return 1\n") + "}\n" +
+		"func b() {\n" + Region("B", "return 2\n") + "}\n"
+
+	tests := []struct {
+		name        string
+		current     string
+		want        string
+		wantCarried []string
+	}{
+		{
+			name:    "nothing edited",
+			current: "func a() {\n" + Region("A", "return 0\n") + "}\n",
+			want:    generated,
+		},
+		{
+			name: "edited body carried",
+			current: "func a() {\n" + regionBegin + "A " + regionHash("return 0\n") + "\nreturn 42\n" + regionEnd + "A\n}\n" +
+				"func b() {\n" + Region("B", "return 0\n") + "}\n",
+			want: "func a() {\n" + regionBegin + "A " + regionHash("return 1\n") + "\nreturn 42\n" + regionEnd + "A\n}\n" +
+				"func b() {\n" + Region("B", "return 2\n") + "}\n",
+			wantCarried: []string{"A"},
+		},
+		{
+			name:    "orphan kept in a comment",
+			current: regionBegin + "C " + regionHash("return 3\n") + "\nreturn 33\n" + regionEnd + "C\n",
+			want: generated + "\n/*\n" + orphanComment + "\n\n" +
+				regionBegin + "C " + regionHash("return 3\n") + "\nreturn 33\n" + regionEnd + "C\n*/\n",
+		},
+		{
+			name: "orphan restored",
+			current: "func a() {\n" + Region("A", "return 1\n") + "}\n" +
+				"\n/*\n" + orphanComment + "\n\n" +
+				regionBegin + "B " + regionHash("return 2\n") + "\nreturn 22\n" + regionEnd + "B\n*/\n",
+			want: "func a() {\n" + Region("A", "return 1\n") + "}\n" +
+				"func b() {\n" + regionBegin + "B " + regionHash("return 2\n") + "\nreturn 22\n" + regionEnd + "B\n}\n",
+			wantCarried: []string{"B"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, carried := mergeRegions([]byte(generated), parseRegions([]byte(tt.current)))
+			if string(got) != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+
+			var names []string
+			for _, r := range carried {
+				names = append(names, r.name)
+			}
+			if !slices.Equal(names, tt.wantCarried) {
+				t.Fatalf("got carried %v, want %v", names, tt.wantCarried)
+			}
+		})
+	}
+}
+
+func TestCarryImports(t *testing.T) {
+	current := []byte(`package service
+
+import (
+	"errors"
+	str "strings"
+	"wiz/models"
+)
+`)
+	carried := []region{{name: "A", body: "return errors.New(str.ToUpper(\"no\"))\n"}}
+
+	tests := []struct {
+		name      string
+		generated string
+		want      string
+	}{
+		{
+			name: "import block",
+			generated: `package service
+
+import (
+	"wiz/models"
+)
+`,
+			want: `package service
+
+import (
+"errors"
+str "strings"
+	"wiz/models"
+)
+`,
+		},
+		{
+			name: "no imports",
+			generated: `package service
+`,
+			want: `package service
+
+import (
+"errors"
+str "strings"
+)
+
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(carryImports([]byte(tt.generated), current, carried))
+			if got != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewGenKeepsEditedRegions(t *testing.T) {
+	fs := fsys.NewMemory()
+	path := filepath.Join(t.TempDir(), "user.go")
+	generate := func(body string) string {
+		g, err := NewGen(fs, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = g.File.WriteString("package service\n\nfunc f() error {\n" + Region("F", body) + "}\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = g.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := fs.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	first := generate("return nil\n")
+	edited := strings.Replace(first, "return nil\n", "return errors.New(\"edited\")\n", 1)
+	edited = strings.Replace(edited, "package service\n", "package service\n\nimport \"errors\"\n", 1)
+	err := writeMemory(fs, path, edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := generate("panic(\"implement me\")\n")
+	if !strings.Contains(got, "return errors.New(\"edited\")\n") {
+		t.Fatalf("the edited body is lost\n%s", got)
+	}
+	if !strings.Contains(got, "\"errors\"") {
+		t.Fatalf("the import of the edited body is lost\n%s", got)
+	}
+	if !strings.Contains(got, regionBegin+"F "+regionHash("panic(\"implement me\")\n")) {
+		t.Fatalf("the marker doesn't hold the hash of the new generated body\n%s", got)
+	}
+}
+
+func writeMemory(fs fsys.FS, path, data string) error {
+	f, err := fs.Create(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteString(data)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"gowizard/builder/gen"
 	"gowizard/builder/model"
+	"gowizard/builder/model/chatfw"
+	"gowizard/builder/model/gentags"
+	"gowizard/builder/model/httpfw"
 	"gowizard/builder/model/system"
 	"gowizard/consts"
 	"gowizard/util"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -17,8 +21,15 @@ type LayerController struct {
 	Models  []*system.Model
 
 	HaveHTTP     bool
-	HaveTelebot  bool
+	HaveSwagger  bool
+	HaveGRPC     bool
+	HaveChat     bool
+	HaveConsumer bool
+	HaveCLI      bool
 	HavePostgres bool
+	HaveMySQL    bool
+	HaveMongo    bool
+	HaveRedis    bool
 }
 
 func NewLayerController(
@@ -36,12 +47,18 @@ func NewLayerController(
 		lc.Layers = append(lc.Layers, &system.Layer{
 			Name:   l.Layer,
 			Type:   l.Tag,
+			Poller: l.Poller,
 			Models: &models,
 		})
 	}
 
 	for i := 0; i < len(lc.Layers)-1; i++ {
-		lc.Layers[i].NextLayer = lc.Layers[i+1]
+		next := i + 1
+		// e.g. http and telebot controllers in a row both call the service
+		for lc.Layers[i].IsTransport() && next < len(lc.Layers)-1 && lc.Layers[next].IsTransport() {
+			next++
+		}
+		lc.Layers[i].NextLayer = lc.Layers[next]
 	}
 
 	return &lc
@@ -49,10 +66,22 @@ func NewLayerController(
 
 func (lc *LayerController) Generate() error {
 	layerTypes := make(map[string]*system.Layer)
+	var httpLayer *system.Layer
 	for _, layer := range lc.Layers {
+		if layer.Type == consts.GRPCLayerType {
+			// protos go first, the layer files use the generated messages
+			err := lc.generateProtoFiles(layer)
+			if err != nil {
+				return err
+			}
+		}
+
 		if layer.Type != "" {
 			layerTypes[layer.Type] = layer
 		}
+		if layer.IsHTTP() || layer.Type == consts.GraphQLLayerType {
+			httpLayer = layer
+		}
 		// generate general file
 		err := lc.generateMainLayerFile(layer)
 		if err != nil {
@@ -64,6 +93,13 @@ func (lc *LayerController) Generate() error {
 			if err != nil {
 				return err
 			}
+
+			if layer.Type == consts.RedisLayerType {
+				err = lc.generateRedisTestFile(layer, (*layer.Models)[i])
+				if err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -77,22 +113,62 @@ func (lc *LayerController) Generate() error {
 		return err
 	}
 
-	if l, ok := layerTypes[consts.HTTPLayerType]; ok {
-		err = lc.generateRouter(l)
+	if httpLayer != nil && httpLayer.Type == consts.GraphQLLayerType {
+		err = lc.generateGraphQLRouter(httpLayer)
+		if err != nil {
+			return err
+		}
+	} else if httpLayer != nil {
+		err = lc.generateRouter(httpLayer)
 		if err != nil {
 			return err
 		}
 	}
 
-	if l, ok := layerTypes[consts.TelebotLayerType]; ok {
-		err = lc.generateTelegramRouter(l)
+	if lc.HaveChat {
+		err = lc.generateChatPackage()
 		if err != nil {
 			return err
 		}
 	}
 
-	if lc.HavePostgres {
-		err = lc.generateDefaultPostgresDockerCompose()
+	for _, layer := range lc.Layers {
+		if layer.IsChat() {
+			err = lc.generateChatRouter(layer)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if l, ok := layerTypes[consts.GRPCLayerType]; ok {
+		err = lc.generateGRPCServer(l)
+		if err != nil {
+			return err
+		}
+	}
+
+	if l, ok := layerTypes[consts.ConsumerLayerType]; ok {
+		err = lc.generateBroker()
+		if err != nil {
+			return err
+		}
+
+		err = lc.generateSubscriber(l)
+		if err != nil {
+			return err
+		}
+	}
+
+	if l, ok := layerTypes[consts.CLILayerType]; ok {
+		err = lc.generateCommands(l)
+		if err != nil {
+			return err
+		}
+	}
+
+	if lc.HavePostgres || lc.HaveMySQL || lc.HaveMongo || lc.HaveRedis || lc.HaveConsumer {
+		err = lc.generateDockerCompose()
 		if err != nil {
 			return err
 		}
@@ -107,7 +183,7 @@ func (lc *LayerController) Generate() error {
 }
 
 func (lc *LayerController) generateMainFile() error {
-	g, err := gen.NewGen(filepath.Join(lc.Builder.Path, "main.go"))
+	g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, "main.go"))
 	if err != nil {
 		return fmt.Errorf("unable to create new main generator: %w", err)
 	}
@@ -118,19 +194,44 @@ func (lc *LayerController) generateMainFile() error {
 	}
 
 	var httpLayer *system.Layer
-	var postgresLayer *system.Layer
-	var telebotLayer *system.Layer
+	var gormLayer *system.Layer
+	var mongoLayer *system.Layer
+	var redisLayer *system.Layer
+	var chatLayer *system.Layer
+	var grpcLayer *system.Layer
+	var consumerLayer *system.Layer
+	var cliLayer *system.Layer
 	for i := range lc.Layers {
-		if lc.Layers[i].Type == consts.HTTPLayerType {
+		if lc.Layers[i].IsHTTP() || lc.Layers[i].Type == consts.GraphQLLayerType {
 			httpLayer = lc.Layers[i]
 		}
 
-		if lc.Layers[i].Type == consts.RepoLayerType {
-			postgresLayer = lc.Layers[i]
+		if lc.Layers[i].IsGorm() {
+			gormLayer = lc.Layers[i]
+		}
+
+		if lc.Layers[i].Type == consts.MongoLayerType {
+			mongoLayer = lc.Layers[i]
+		}
+
+		if lc.Layers[i].Type == consts.RedisLayerType {
+			redisLayer = lc.Layers[i]
+		}
+
+		if lc.Layers[i].IsChat() {
+			chatLayer = lc.Layers[i]
+		}
+
+		if lc.Layers[i].Type == consts.GRPCLayerType {
+			grpcLayer = lc.Layers[i]
 		}
 
-		if lc.Layers[i].Type == consts.TelebotLayerType {
-			telebotLayer = lc.Layers[i]
+		if lc.Layers[i].Type == consts.ConsumerLayerType {
+			consumerLayer = lc.Layers[i]
+		}
+
+		if lc.Layers[i].Type == consts.CLILayerType {
+			cliLayer = lc.Layers[i]
 		}
 	}
 
@@ -143,19 +244,47 @@ func (lc *LayerController) generateMainFile() error {
 			util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultRouterFolder)),
 		)
 	}
-	if postgresLayer != nil {
+	if gormLayer != nil {
+		// sqlite takes the file path as is, so there is no dsn to format
+		if gormLayer.Type != consts.SQLiteLayerType {
+			importsToAdd = append(importsToAdd, util.MakeString("fmt"))
+		}
 		importsToAdd = append(importsToAdd,
-			util.MakeString("fmt"),
 			util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultModelsFolder)),
 			util.MakeString(consts.GormURL),
-			util.MakeString(consts.GormPostgresDriverURL),
+			util.MakeString(gormDriverURL(gormLayer)),
+		)
+	}
+	if mongoLayer != nil {
+		importsToAdd = append(importsToAdd,
+			util.MakeString(consts.MongoURL),
+			util.MakeString(consts.MongoOptionsURL),
+		)
+	}
+	if redisLayer != nil {
+		importsToAdd = append(importsToAdd, util.MakeString(consts.RedisURL))
+	}
+	if chatLayer != nil {
+		platform := lc.chatPlatform(chatLayer)
+		importsToAdd = append(importsToAdd, platform.MainImports()...)
+		importsToAdd = append(importsToAdd,
+			util.MakeString(filepath.Join(lc.Builder.ProjectName, platform.Folder())),
+		)
+	}
+	if grpcLayer != nil {
+		importsToAdd = append(importsToAdd,
+			util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultGRPCServerFolder)),
+		)
+	}
+	if consumerLayer != nil {
+		importsToAdd = append(importsToAdd,
+			util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultBrokerFolder)),
+			util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultSubscriberFolder)),
 		)
 	}
-	if telebotLayer != nil {
+	if cliLayer != nil {
 		importsToAdd = append(importsToAdd,
-			util.MakeString("time"),
-			util.MakeString(consts.TelebotURL),
-			util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultTelerouterFolder)),
+			util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultCommandsFolder)),
 		)
 	}
 	for i := range lc.Layers {
@@ -167,33 +296,37 @@ func (lc *LayerController) generateMainFile() error {
 		return fmt.Errorf("unable to add imports: %w", err)
 	}
 
+	if grpcLayer != nil {
+		_, err = g.File.WriteString(fmt.Sprintf("//go:generate %s\n\n", strings.Join(append([]string{"protoc"}, lc.ProtocArgs()...), " ")))
+		if err != nil {
+			return err
+		}
+	}
+
 	_, err = g.File.WriteString("func main() {\n")
 	if err != nil {
 		return err
 	}
 
-	_, err = g.File.WriteString(fmt.Sprintf("%s, err := %s.New%s()\nif err != nil {\npanic(err.Error())\n}\n\n",
-		consts.DefaultConfigFolder, consts.DefaultConfigFolder, util.MakePublicName(consts.DefaultConfigFolder)))
+	_, err = g.File.WriteString(fmt.Sprintf("%s, err := %s.New%s()\nif err != nil {\npanic(err.Error())\n}\n\nerr = %s.Validate()\nif err != nil {\npanic(err.Error())\n}\n\n",
+		consts.DefaultConfigFolder, consts.DefaultConfigFolder, util.MakePublicName(consts.DefaultConfigFolder), consts.DefaultConfigFolder))
 	if err != nil {
 		return err
 	}
 
-	if postgresLayer != nil {
-		_, err = g.File.WriteString(
-			fmt.Sprintf("dsn := fmt.Sprintf(\"host = %%s user = %%s password = %%s dbname = %%s port = %%s sslmode=disable\", %s.PostgresHost, %s.PostgresUser, %s.PostgresPassword, %s.PostgresDb, %s.PostgresPort)\n",
-				consts.DefaultConfigFolder, consts.DefaultConfigFolder, consts.DefaultConfigFolder, consts.DefaultConfigFolder, consts.DefaultConfigFolder),
-		)
+	if gormLayer != nil {
+		_, err = g.File.WriteString(gormDSN(gormLayer))
 		if err != nil {
 			return err
 		}
 
-		_, err = g.File.WriteString(`db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		_, err = g.File.WriteString(fmt.Sprintf(`db, err := gorm.Open(%s.Open(dsn), &gorm.Config{})
 if err != nil {
-panic(err.Error)
+panic(err.Error())
 }
 
 err = db.AutoMigrate(
-`)
+`, gormLayer.Type))
 		if err != nil {
 			return err
 		}
@@ -215,12 +348,46 @@ panic(err.Error())
 		}
 	}
 
-	if telebotLayer != nil {
-		_, err = g.File.WriteString(fmt.Sprintf(`bot, err := telebot.NewBot(telebot.Settings{
-Token: %s.TelebotToken,
-Poller: &telebot.LongPoller{Timeout: 10*time.Second},
+	if mongoLayer != nil {
+		_, err = g.File.WriteString(fmt.Sprintf(`client, err := mongo.Connect(options.Client().ApplyURI(%s.MongoUri))
+if err != nil {
+panic(err.Error())
+}
+
+db := client.Database(%s.MongoDb)
+
+`, consts.DefaultConfigFolder, consts.DefaultConfigFolder))
+		if err != nil {
+			return err
+		}
+	}
+
+	if redisLayer != nil {
+		_, err = g.File.WriteString(fmt.Sprintf(`rdb := redis.NewClient(&redis.Options{
+Addr: %s.RedisAddr,
+Password: %s.RedisPassword,
 })
-`, consts.DefaultConfigFolder))
+
+`, consts.DefaultConfigFolder, consts.DefaultConfigFolder))
+		if err != nil {
+			return err
+		}
+	}
+
+	if chatLayer != nil {
+		_, err = g.File.WriteString(lc.chatPlatform(chatLayer).MainClient(consts.DefaultConfigFolder))
+		if err != nil {
+			return err
+		}
+	}
+
+	if consumerLayer != nil {
+		_, err = g.File.WriteString(fmt.Sprintf(`brk, err := %s.New(%s)
+if err != nil {
+panic(err.Error())
+}
+
+`, consts.DefaultBrokerFolder, consts.DefaultConfigFolder))
 		if err != nil {
 			return err
 		}
@@ -229,14 +396,14 @@ Poller: &telebot.LongPoller{Timeout: 10*time.Second},
 	for i := len(lc.Layers) - 1; i >= 0; i-- {
 		for _, mdl := range lc.Models {
 			args := "config"
-			if i < len(lc.Layers)-1 {
-				args = fmt.Sprintf("%s, %s%s", args, mdl.Name, util.MakePublicName(lc.Layers[i+1].Name))
+			if lc.Layers[i].NextLayer != nil {
+				args = fmt.Sprintf("%s, %s%s", args, mdl.Name, util.MakePublicName(lc.Layers[i].NextLayer.Name))
 			}
-			if lc.Layers[i].Type == consts.RepoLayerType {
+			if lc.Layers[i].IsGorm() || lc.Layers[i].Type == consts.MongoLayerType {
 				args = fmt.Sprintf("%s, db", args)
 			}
-			if lc.Layers[i].Type == consts.TelebotLayerType {
-				args = fmt.Sprintf("%s, bot", args)
+			if lc.Layers[i].Type == consts.RedisLayerType {
+				args = fmt.Sprintf("%s, rdb", args)
 			}
 
 			_, err = g.File.WriteString(fmt.Sprintf("%s%s := %s.New%s%s(%s)\n", mdl.Name, util.MakePublicName(lc.Layers[i].Name), lc.Layers[i].Name, mdl.Name, util.MakePublicName(lc.Layers[i].Name), args))
@@ -251,38 +418,69 @@ Poller: &telebot.LongPoller{Timeout: 10*time.Second},
 		}
 	}
 
+	// every entry point but the last one runs in its own goroutine
+	entries := make([]string, 0, 2)
 	if httpLayer != nil {
-		args := lc.Models[0].Name + util.MakePublicName(httpLayer.Name)
-		for i := range lc.Models[1:] {
-			args += ", " + lc.Models[i+1].Name + util.MakePublicName(httpLayer.Name)
+		args := layerArgs(lc.Models, httpLayer) + ", " + consts.DefaultConfigFolder
+		if lc.sharesWebhook() {
+			args += ", webhook"
 		}
-		args += ", " + consts.DefaultConfigFolder
 
 		_, err = g.File.WriteString(fmt.Sprintf("r := %s.New%s(%s)\n", consts.DefaultRouterFolder, util.MakePublicName(consts.DefaultRouterFolder), args))
 		if err != nil {
 			return err
 		}
+		entries = append(entries, "r")
+	}
+
+	if chatLayer != nil {
+		platform := chatfw.Select(chatLayer.Type)
+		args := layerArgs(lc.Models, chatLayer) + ", " + consts.DefaultConfigFolder + ", bot"
+
+		_, err = g.File.WriteString(fmt.Sprintf("cr := %s.New%s(%s)\n", platform.Folder(), platform.RouterName(), args))
+		if err != nil {
+			return err
+		}
+		entries = append(entries, "cr")
+	}
 
-		_, err = g.File.WriteString("r.Run()")
+	if grpcLayer != nil {
+		args := layerArgs(lc.Models, grpcLayer) + ", " + consts.DefaultConfigFolder
+
+		_, err = g.File.WriteString(fmt.Sprintf("s := %s.NewServer(%s)\n", consts.DefaultGRPCServerFolder, args))
 		if err != nil {
 			return err
 		}
+		entries = append(entries, "s")
 	}
 
-	if telebotLayer != nil {
-		args := lc.Models[0].Name + util.MakePublicName(telebotLayer.Name)
-		for i := range lc.Models[1:] {
-			args += ", " + lc.Models[i+1].Name + util.MakePublicName(telebotLayer.Name)
+	if consumerLayer != nil {
+		args := layerArgs(lc.Models, consumerLayer) + ", " + consts.DefaultConfigFolder + ", brk"
+
+		_, err = g.File.WriteString(fmt.Sprintf("sub := %s.NewSubscriber(%s)\n", consts.DefaultSubscriberFolder, args))
+		if err != nil {
+			return err
 		}
-		args += ", " + consts.DefaultConfigFolder
-		args += ", bot"
+		entries = append(entries, "sub")
+	}
+
+	if cliLayer != nil {
+		args := layerArgs(lc.Models, cliLayer) + ", " + consts.DefaultConfigFolder
 
-		_, err = g.File.WriteString(fmt.Sprintf("r := %s.NewTeleRouter(%s)\n", consts.DefaultTelerouterFolder, args))
+		_, err = g.File.WriteString(fmt.Sprintf("c := %s.NewCommands(%s)\n", consts.DefaultCommandsFolder, args))
 		if err != nil {
 			return err
 		}
+		entries = append(entries, "c")
+	}
+
+	for i, entry := range entries {
+		run := entry + ".Run()\n"
+		if i < len(entries)-1 {
+			run = "go " + run
+		}
 
-		_, err = g.File.WriteString("r.Run()")
+		_, err = g.File.WriteString(run)
 		if err != nil {
 			return err
 		}
@@ -293,28 +491,67 @@ Poller: &telebot.LongPoller{Timeout: 10*time.Second},
 		return err
 	}
 
-	return nil
+	return g.Close()
+}
+
+// layerArgs are the model instances of the layer built in main.go, e.g. UserController, CarController
+func layerArgs(mdls []*system.Model, layer *system.Layer) string {
+	args := make([]string, 0, len(mdls))
+	for _, mdl := range mdls {
+		args = append(args, mdl.Name+util.MakePublicName(layer.Name))
+	}
+
+	return strings.Join(args, ", ")
+}
+
+// chatPlatform is the platform of the chat layer set up with the layer options from the spec
+func (lc *LayerController) chatPlatform(layer *system.Layer) chatfw.Platform {
+	if layer.Type == consts.TelebotLayerType {
+		return &chatfw.Telebot{
+			Webhook: layer.Poller == consts.TelebotWebhookPoller,
+			Shared:  lc.sharesWebhook(),
+		}
+	}
+
+	return chatfw.Select(layer.Type)
+}
+
+// sharesWebhook reports whether the telebot webhook is served by the http router instead of its own listener
+func (lc *LayerController) sharesWebhook() bool {
+	var webhook, http bool
+	for _, layer := range lc.Layers {
+		webhook = webhook || layer.Type == consts.TelebotLayerType && layer.Poller == consts.TelebotWebhookPoller
+		http = http || layer.IsHTTP()
+	}
+
+	return webhook && http
 }
 
 func (lc *LayerController) generateMainLayerFile(layer *system.Layer) error {
-	g, err := gen.NewGen(layer.Path + layer.Name + ".go")
+	g, err := gen.NewGen(lc.Builder.FS, layer.Path+layer.Name+".go")
 	if err != nil {
 		return fmt.Errorf("unable to create new generator: %w", err)
 	}
 
-	defer g.Close()
-
 	err = g.AddPackage(layer.Name)
 	if err != nil {
 		return fmt.Errorf("unable to add package %s: %w", layer.Name, err)
 	}
 
 	imports := make([]string, 0, 2)
-	switch layer.Type {
-	case consts.HTTPLayerType:
-		imports = append(imports, util.MakeString("github.com/gin-gonic/gin"))
-	case consts.TelebotLayerType:
-		imports = append(imports, util.MakeString(consts.TelebotURL))
+	switch {
+	case layer.IsHTTP():
+		imports = append(imports, httpfw.Select(layer.Type).Imports()...)
+	case layer.IsChat():
+		imports = append(imports, chatfw.Select(layer.Type).Imports(lc.Builder.ProjectName)...)
+	case layer.Type == consts.ConsumerLayerType:
+		imports = append(imports, util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultBrokerFolder)))
+	case layer.Type == consts.CLILayerType:
+		imports = append(imports, util.MakeString(consts.CobraURL))
+	case layer.Type == consts.GRPCLayerType:
+		imports = append(imports, util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultProtoFolder)))
+	case layer.Type == consts.GraphQLLayerType:
+		// the interfaces only use the resolver types of the layer
 	default:
 		imports = append(imports, util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultModelsFolder)))
 	}
@@ -326,20 +563,54 @@ func (lc *LayerController) generateMainLayerFile(layer *system.Layer) error {
 	// Generate layer general file
 	for j, mdl := range *layer.Models {
 		methods := make([]model.InterfaceMethodInstance, 0, len(mdl.Methods))
-		switch layer.Type {
-		case consts.HTTPLayerType:
+		switch {
+		case layer.IsHTTP():
 			for _, method := range (*layer.Models)[j].Methods {
 				methods = append(methods, model.InterfaceMethodInstance{
-					Name: method.String() + mdl.Name,
-					Args: []string{"ctx", "*gin.Context"},
+					Name:    method.String() + mdl.Name,
+					Args:    method.GetDefaultArgs(mdl, layer),
+					Returns: httpfw.Select(layer.Type).Returns(),
+				})
+			}
+
+		case layer.IsChat():
+			for _, method := range (*layer.Models)[j].Methods {
+				methods = append(methods, model.InterfaceMethodInstance{
+					Name:    method.String() + mdl.Name,
+					Args:    method.GetDefaultArgs(mdl, layer),
+					Returns: chatfw.Select(layer.Type).Returns(),
 				})
 			}
 
-		case consts.TelebotLayerType:
+		case layer.Type == consts.ConsumerLayerType:
 			for _, method := range (*layer.Models)[j].Methods {
 				methods = append(methods, model.InterfaceMethodInstance{
 					Name: method.String() + mdl.Name,
-					Args: []string{"m", "*telebot.Message"},
+					Args: method.GetDefaultArgs(mdl, layer),
+				})
+			}
+
+		case layer.Type == consts.CLILayerType:
+			for _, method := range (*layer.Models)[j].Methods {
+				methods = append(methods, model.InterfaceMethodInstance{
+					Name:    method.String() + mdl.Name,
+					Args:    method.GetDefaultArgs(mdl, layer),
+					Returns: []string{"error"},
+				})
+			}
+
+		case layer.Type == consts.GRPCLayerType:
+			methods = append(methods, model.InterfaceMethodInstance{
+				Name:     fmt.Sprintf("%s.%sServiceServer", consts.DefaultProtoFolder, mdl.Name),
+				Embedded: true,
+			})
+
+		case layer.Type == consts.GraphQLLayerType:
+			for _, method := range (*layer.Models)[j].Methods {
+				methods = append(methods, model.InterfaceMethodInstance{
+					Name:    method.String() + mdl.Name,
+					Args:    method.GetDefaultArgs(mdl, layer),
+					Returns: gentags.NewGraphQL(layer, mdl).Returns(method),
 				})
 			}
 
@@ -360,15 +631,20 @@ func (lc *LayerController) generateMainLayerFile(layer *system.Layer) error {
 				mdl.Name, layer.Name, err)
 		}
 	}
+
+	err = g.Close()
+	if err != nil {
+		return fmt.Errorf("unable to close file %s: %w", layer.Name, err)
+	}
+
 	return nil
 }
 
 func (lc *LayerController) generateModelLayerFile(layer *system.Layer, mdl *system.Model) error {
-	g, err := gen.NewGen(layer.Path + mdl.GetFilename())
+	g, err := gen.NewGen(lc.Builder.FS, layer.Path+mdl.GetFilename())
 	if err != nil {
 		return fmt.Errorf("unable to create new generator: %w", err)
 	}
-	defer g.Close()
 
 	err = g.AddPackage(layer.Name)
 	if err != nil {
@@ -395,22 +671,49 @@ func (lc *LayerController) generateModelLayerFile(layer *system.Layer, mdl *syst
 	}
 
 	switch layer.Type {
-	case consts.HTTPLayerType:
-		importsToAdd = append(importsToAdd, util.MakeString(consts.GinURL))
-	case consts.RepoLayerType:
-		importsToAdd = append(importsToAdd, util.MakeString("gorm.io/gorm"))
+	case consts.HTTPLayerType, consts.NetHTTPLayerType, consts.EchoLayerType, consts.FiberLayerType:
+		importsToAdd = append(importsToAdd, gentags.NewHTTP(layer, mdl).Imports()...)
+	case consts.RepoLayerType, consts.MySQLLayerType, consts.SQLiteLayerType:
+		importsToAdd = append(importsToAdd, util.MakeString(consts.GormURL))
 		privateMdl.Fields = append(privateMdl.Fields, system.Field{
 			Name: "db",
 			Type: "*gorm.DB",
 		})
-	case consts.TelebotLayerType:
-		importsToAdd = append(importsToAdd, util.MakeString(consts.TelebotURL))
-		importsToAdd = append(importsToAdd, util.MakeString("encoding/json"))
-		importsToAdd = append(importsToAdd, util.MakeString("strings"))
+	case consts.GRPCLayerType:
+		importsToAdd = append(importsToAdd,
+			util.MakeString("context"),
+			util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultProtoFolder)),
+		)
+		privateMdl.Fields = append([]system.Field{{
+			Name: fmt.Sprintf("%s.Unimplemented%sServiceServer", consts.DefaultProtoFolder, mdl.Name),
+		}}, privateMdl.Fields...)
+	case consts.MongoLayerType:
+		importsToAdd = append(importsToAdd, gentags.NewMongo(layer, mdl).Imports()...)
+		privateMdl.Fields = append(privateMdl.Fields, system.Field{
+			Name: "db",
+			Type: "*mongo.Database",
+		})
+	case consts.MemoryLayerType:
+		importsToAdd = append(importsToAdd, gentags.NewMemory(layer, mdl).Imports()...)
+		privateMdl.Fields = append(privateMdl.Fields, gentags.NewMemory(layer, mdl).Fields()...)
+	case consts.RedisLayerType:
+		importsToAdd = append(importsToAdd, gentags.NewRedis(layer, mdl).Imports()...)
 		privateMdl.Fields = append(privateMdl.Fields, system.Field{
-			Name: "bot",
-			Type: "*telebot.Bot",
+			Name: "rdb",
+			Type: "*redis.Client",
 		})
+	case consts.TelebotLayerType, consts.DiscordLayerType, consts.SlackLayerType:
+		importsToAdd = append(importsToAdd, gentags.NewChat(layer, mdl).Imports(lc.Builder.ProjectName)...)
+	case consts.ConsumerLayerType:
+		importsToAdd = append(importsToAdd,
+			util.MakeString("encoding/json"),
+			util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultBrokerFolder)),
+		)
+	case consts.CLILayerType:
+		importsToAdd = append(importsToAdd,
+			util.MakeString("encoding/json"),
+			util.MakeString(consts.CobraURL),
+		)
 	}
 
 	err = g.AddImport(importsToAdd)
@@ -437,32 +740,118 @@ func (lc *LayerController) generateModelLayerFile(layer *system.Layer, mdl *syst
 		}
 		genMethod.UpdateByMethodType()
 
-		if layer.Type == consts.HTTPLayerType {
-			genMethod.Returns = []string{""}
-			err = g.AddMethodWithSwagger(&privateMdl, &genMethod)
-			if err != nil {
-				return fmt.Errorf("unable to add method %s with swagger: %w", mdl.Name, err)
+		if fw := httpfw.Select(layer.Type); fw != nil {
+			genMethod.Returns = fw.Returns()
+			if len(genMethod.Returns) == 0 {
+				genMethod.Returns = []string{""}
 			}
 
-			continue
+			if fw.Swagger() {
+				err = g.AddMethodWithSwagger(&privateMdl, &genMethod)
+				if err != nil {
+					return fmt.Errorf("unable to add method %s with swagger: %w", mdl.Name, err)
+				}
+
+				continue
+			}
 		}
 
-		if layer.Type == consts.TelebotLayerType {
+		if platform := chatfw.Select(layer.Type); platform != nil {
+			genMethod.Returns = platform.Returns()
+		}
+
+		if layer.Type == consts.ConsumerLayerType {
 			genMethod.Returns = []string{""}
 		}
 
+		if layer.Type == consts.CLILayerType {
+			genMethod.Returns = []string{"error"}
+		}
+
+		if layer.Type == consts.GRPCLayerType {
+			genMethod.Returns = gentags.NewGRPC(layer, mdl).Returns(iMdl)
+		}
+
+		if layer.Type == consts.GraphQLLayerType {
+			genMethod.Returns = gentags.NewGraphQL(layer, mdl).Returns(iMdl)
+		}
+
 		err = g.AddMethod(&privateMdl, &genMethod)
 		if err != nil {
 			return fmt.Errorf("unable to add method %s: %w", mdl.Name, err)
 		}
 	}
 
+	if layer.Type == consts.GRPCLayerType {
+		_, err = g.File.WriteString(gentags.NewGRPC(layer, mdl).Converters())
+		if err != nil {
+			return fmt.Errorf("unable to add proto converters %s: %w", mdl.Name, err)
+		}
+	}
+
+	if layer.Type == consts.GraphQLLayerType {
+		_, err = g.File.WriteString(gentags.NewGraphQL(layer, mdl).Types())
+		if err != nil {
+			return fmt.Errorf("unable to add graphql types %s: %w", mdl.Name, err)
+		}
+	}
+
+	if layer.Type == consts.MemoryLayerType {
+		_, err = g.File.WriteString(gentags.NewMemory(layer, mdl).Match())
+		if err != nil {
+			return fmt.Errorf("unable to add memory filter %s: %w", mdl.Name, err)
+		}
+	}
+
+	if layer.Type == consts.RedisLayerType {
+		_, err = g.File.WriteString(gentags.NewRedis(layer, mdl).Invalidate())
+		if err != nil {
+			return fmt.Errorf("unable to add redis invalidation %s: %w", mdl.Name, err)
+		}
+	}
+
+	err = g.Close()
+	if err != nil {
+		return fmt.Errorf("unable to close file %s: %w", mdl.Name, err)
+	}
+
 	return nil
 }
 
+// generateRedisTestFile writes the cache tests of the model, they run against an in-process redis
+func (lc *LayerController) generateRedisTestFile(layer *system.Layer, mdl *system.Model) error {
+	cache := gentags.NewRedis(layer, mdl)
+	tests := cache.Tests()
+	if tests == "" {
+		return nil
+	}
+
+	g, err := gen.NewGen(lc.Builder.FS, layer.Path+strings.TrimSuffix(mdl.GetFilename(), ".go")+"_test.go")
+	if err != nil {
+		return fmt.Errorf("unable to create new generator: %w", err)
+	}
+
+	err = g.AddPackage(layer.Name)
+	if err != nil {
+		return fmt.Errorf("unable to add package %s: %w", layer.Name, err)
+	}
+
+	err = g.AddImport(cache.TestImports(lc.Builder.ProjectName))
+	if err != nil {
+		return fmt.Errorf("unable to add imports %s: %w", mdl.Name, err)
+	}
+
+	_, err = g.File.WriteString(tests)
+	if err != nil {
+		return fmt.Errorf("unable to write tests %s: %w", mdl.Name, err)
+	}
+
+	return g.Close()
+}
+
 func (lc *LayerController) generateModelStorageFile() error {
 	for _, mdl := range lc.Models {
-		g, err := gen.NewGen(filepath.Join(lc.Builder.Path, consts.DefaultModelsFolder, mdl.GetFilename()))
+		g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.DefaultModelsFolder, mdl.GetFilename()))
 		if err != nil {
 			return fmt.Errorf("unable to create new generator: %w", err)
 		}
@@ -472,6 +861,18 @@ func (lc *LayerController) generateModelStorageFile() error {
 			return fmt.Errorf("unable to add package %s: %w", consts.DefaultModelsFolder, err)
 		}
 
+		if lc.HaveMongo {
+			if mdl.GetIDField() != nil {
+				err = g.AddImport([]string{util.MakeString(consts.MongoBsonURL)})
+				if err != nil {
+					return fmt.Errorf("unable to add imports %s: %w", mdl.Name, err)
+				}
+			}
+
+			g.Tags = append(g.Tags, "bson")
+			mdl = mdl.ForMongo()
+		}
+
 		err = g.AddStruct(mdl)
 		if err != nil {
 			return fmt.Errorf("unable to add struct %s: %w", mdl.Name, err)
@@ -486,24 +887,62 @@ func (lc *LayerController) generateModelStorageFile() error {
 	return nil
 }
 
-func (lc *LayerController) generateTelegramRouter(layer *system.Layer) error {
-	g, err := gen.NewGen(filepath.Join(lc.Builder.Path, consts.DefaultTelerouterFolder, consts.DefaultTelerouterFolder+".go"))
+// generateChatPackage writes the chat package: the platform independent command context,
+// the platform adapters and the fakes used to test the handlers
+func (lc *LayerController) generateChatPackage() error {
+	files := []chatfw.File{{Name: "context", Source: chatfw.ContextSource}}
+	for _, layer := range lc.Layers {
+		if layer.IsChat() {
+			files = append(files, chatfw.Select(layer.Type).Sources(lc.Builder.ProjectName)...)
+		}
+	}
+
+	for _, file := range files {
+		g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.DefaultChatFolder, file.Name+".go"))
+		if err != nil {
+			return fmt.Errorf("unable to create new generator: %w", err)
+		}
+
+		err = g.AddPackage(consts.DefaultChatFolder)
+		if err != nil {
+			return fmt.Errorf("unable to add package %s: %w", consts.DefaultChatFolder, err)
+		}
+
+		err = g.AddImport(file.Imports)
+		if err != nil {
+			return fmt.Errorf("unable to add imports %s: %w", file.Name, err)
+		}
+
+		_, err = g.File.WriteString(file.Source)
+		if err != nil {
+			return fmt.Errorf("unable to write chat %s: %w", file.Name, err)
+		}
+
+		err = g.Close()
+		if err != nil {
+			return fmt.Errorf("unable to close file %s: %w", file.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (lc *LayerController) generateChatRouter(layer *system.Layer) error {
+	platform := chatfw.Select(layer.Type)
+	g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, platform.Folder(), platform.Folder()+".go"))
 	if err != nil {
 		return fmt.Errorf("unable to create new generator: %w", err)
 	}
 
-	err = g.AddPackage(
-		consts.DefaultTelerouterFolder,
-	)
+	err = g.AddPackage(platform.Folder())
 	if err != nil {
 		return fmt.Errorf("unable to create add packages")
 	}
 
-	err = g.AddImport([]string{
-		util.MakeString(consts.TelebotURL),
+	err = g.AddImport(append(platform.RouterImports(lc.Builder.ProjectName),
 		util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultConfigFolder)),
 		util.MakeString(filepath.Join(lc.Builder.ProjectName, layer.Name)),
-	})
+	))
 	if err != nil {
 		return fmt.Errorf("unable to add imports")
 	}
@@ -515,7 +954,7 @@ func (lc *LayerController) generateTelegramRouter(layer *system.Layer) error {
 	})
 	routerFields = append(routerFields, system.Field{
 		Name: "Bot",
-		Type: "*" + system.FieldType("telebot.Bot"),
+		Type: system.FieldType(platform.Client()),
 	})
 	for i := range *layer.Models {
 		routerFields[i] = system.Field{
@@ -524,20 +963,20 @@ func (lc *LayerController) generateTelegramRouter(layer *system.Layer) error {
 		}
 	}
 	routerModel := &system.Model{
-		Name:   "TeleRouter",
+		Name:   platform.RouterName(),
 		Fields: routerFields,
 	}
 	err = g.AddStruct(routerModel)
 	if err != nil {
-		return fmt.Errorf("unable to add struct Router")
+		return fmt.Errorf("unable to add struct %s", routerModel.Name)
 	}
 
-	err = g.AddMainTelerouterNewFunc(routerModel)
+	err = g.AddMainRouterNewFunc(routerModel)
 	if err != nil {
 		return err
 	}
 
-	err = g.AddMainTeleRouterFunc(lc.Models)
+	err = g.AddMainChatRouterFunc(lc.Models, platform)
 	if err != nil {
 		return fmt.Errorf("unable to add main router file")
 	}
@@ -550,8 +989,415 @@ func (lc *LayerController) generateTelegramRouter(layer *system.Layer) error {
 	return nil
 }
 
+// generateBroker writes the broker package, the broker itself is chosen by the config at startup
+func (lc *LayerController) generateBroker() error {
+	files := []struct {
+		name    string
+		imports []string
+		source  string
+	}{
+		{
+			name: consts.DefaultBrokerFolder,
+			imports: []string{
+				util.MakeString("fmt"),
+				util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultConfigFolder)),
+			},
+			source: fmt.Sprintf(gentags.BrokerSource, util.MakeString(streamName(lc.Builder.ProjectName))),
+		},
+		{
+			name: "memory",
+			imports: []string{
+				util.MakeString("fmt"),
+				util.MakeString("sync"),
+			},
+			source: gentags.MemoryBrokerSource,
+		},
+		{
+			name: "nats",
+			imports: []string{
+				util.MakeString("errors"),
+				util.MakeString("strings"),
+				util.MakeString("sync"),
+				util.MakeString(consts.NatsURL),
+			},
+			source: gentags.NatsBrokerSource,
+		},
+		{
+			name: "rabbitmq",
+			imports: []string{
+				util.MakeString("context"),
+				util.MakeString("strings"),
+				util.MakeString("sync"),
+				util.MakeString(consts.RabbitMQURL),
+			},
+			source: gentags.RabbitMQBrokerSource,
+		},
+	}
+
+	for _, file := range files {
+		g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.DefaultBrokerFolder, file.name+".go"))
+		if err != nil {
+			return fmt.Errorf("unable to create new generator: %w", err)
+		}
+
+		err = g.AddPackage(consts.DefaultBrokerFolder)
+		if err != nil {
+			return fmt.Errorf("unable to add package %s: %w", consts.DefaultBrokerFolder, err)
+		}
+
+		err = g.AddImport(file.imports)
+		if err != nil {
+			return fmt.Errorf("unable to add imports %s: %w", file.name, err)
+		}
+
+		_, err = g.File.WriteString(file.source)
+		if err != nil {
+			return fmt.Errorf("unable to write broker %s: %w", file.name, err)
+		}
+
+		err = g.Close()
+		if err != nil {
+			return fmt.Errorf("unable to close file %s: %w", file.name, err)
+		}
+	}
+
+	return nil
+}
+
+// streamName makes a JetStream stream name of the module path, e.g. github.com/acme/shop-api -> SHOP_API
+func streamName(projectName string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(filepath.Base(projectName)))
+}
+
+func (lc *LayerController) generateSubscriber(layer *system.Layer) error {
+	g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.DefaultSubscriberFolder, consts.DefaultSubscriberFolder+".go"))
+	if err != nil {
+		return fmt.Errorf("unable to create new generator: %w", err)
+	}
+
+	err = g.AddPackage(consts.DefaultSubscriberFolder)
+	if err != nil {
+		return fmt.Errorf("unable to create add packages")
+	}
+
+	err = g.AddImport([]string{
+		util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultBrokerFolder)),
+		util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultConfigFolder)),
+		util.MakeString(filepath.Join(lc.Builder.ProjectName, layer.Name)),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to add imports")
+	}
+
+	subscriberFields := make([]system.Field, len(lc.Models))
+	subscriberFields = append(subscriberFields, system.Field{
+		Name: "Config",
+		Type: "*" + system.FieldType(consts.DefaultConfigFolder+"."+util.MakePublicName("Config")),
+	})
+	subscriberFields = append(subscriberFields, system.Field{
+		Name: "Broker",
+		Type: system.FieldType(consts.DefaultBrokerFolder + ".Broker"),
+	})
+	for i := range lc.Models {
+		subscriberFields[i] = system.Field{
+			Name: lc.Models[i].Name,
+			Type: system.FieldType(layer.Name + "." + lc.Models[i].Name),
+		}
+	}
+	subscriberModel := &system.Model{
+		Name:   "Subscriber",
+		Fields: subscriberFields,
+	}
+	err = g.AddStruct(subscriberModel)
+	if err != nil {
+		return fmt.Errorf("unable to add struct Subscriber")
+	}
+
+	err = g.AddMainRouterNewFunc(subscriberModel)
+	if err != nil {
+		return err
+	}
+
+	err = g.AddMainSubscriberFunc(lc.Models)
+	if err != nil {
+		return fmt.Errorf("unable to add main subscriber func")
+	}
+
+	err = g.Close()
+	if err != nil {
+		return fmt.Errorf("unable to close subscriber generator")
+	}
+
+	return nil
+}
+
+// generateCommands writes the cobra command tree calling the cli layer
+func (lc *LayerController) generateCommands(layer *system.Layer) error {
+	g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.DefaultCommandsFolder, consts.DefaultCommandsFolder+".go"))
+	if err != nil {
+		return fmt.Errorf("unable to create new generator: %w", err)
+	}
+
+	err = g.AddPackage(consts.DefaultCommandsFolder)
+	if err != nil {
+		return fmt.Errorf("unable to create add packages")
+	}
+
+	err = g.AddImport([]string{
+		util.MakeString("os"),
+		util.MakeString(consts.CobraURL),
+		util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultConfigFolder)),
+		util.MakeString(filepath.Join(lc.Builder.ProjectName, layer.Name)),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to add imports")
+	}
+
+	commandsFields := make([]system.Field, len(lc.Models))
+	commandsFields = append(commandsFields, system.Field{
+		Name: "Config",
+		Type: "*" + system.FieldType(consts.DefaultConfigFolder+"."+util.MakePublicName("Config")),
+	})
+	for i := range lc.Models {
+		commandsFields[i] = system.Field{
+			Name: lc.Models[i].Name,
+			Type: system.FieldType(layer.Name + "." + lc.Models[i].Name),
+		}
+	}
+	commandsModel := &system.Model{
+		Name:   "Commands",
+		Fields: commandsFields,
+	}
+	err = g.AddStruct(commandsModel)
+	if err != nil {
+		return fmt.Errorf("unable to add struct Commands")
+	}
+
+	err = g.AddMainRouterNewFunc(commandsModel)
+	if err != nil {
+		return err
+	}
+
+	err = g.AddMainCommandsFunc(lc.Models, layer, filepath.Base(lc.Builder.ProjectName))
+	if err != nil {
+		return fmt.Errorf("unable to add main commands func")
+	}
+
+	err = g.Close()
+	if err != nil {
+		return fmt.Errorf("unable to close commands generator")
+	}
+
+	return nil
+}
+
+// ProtocArgs are the protoc arguments compiling every model proto file from the project root
+func (lc *LayerController) ProtocArgs() []string {
+	args := []string{
+		"--go_out=.", "--go_opt=paths=source_relative",
+		"--go-grpc_out=.", "--go-grpc_opt=paths=source_relative",
+	}
+	for _, mdl := range lc.Models {
+		args = append(args, filepath.Join(consts.DefaultProtoFolder, util.PascalToSnakeCase(mdl.Name)+".proto"))
+	}
+
+	return args
+}
+
+func (lc *LayerController) generateProtoFiles(layer *system.Layer) error {
+	for _, mdl := range lc.Models {
+		g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.DefaultProtoFolder, util.PascalToSnakeCase(mdl.Name)+".proto"))
+		if err != nil {
+			return fmt.Errorf("unable to create new generator: %w", err)
+		}
+
+		_, err = g.File.WriteString(gentags.NewGRPC(layer, mdl).Proto(lc.Builder.ProjectName))
+		if err != nil {
+			return fmt.Errorf("unable to write proto %s: %w", mdl.Name, err)
+		}
+
+		err = g.Close()
+		if err != nil {
+			return fmt.Errorf("unable to close file %s: %w", mdl.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (lc *LayerController) generateGRPCServer(layer *system.Layer) error {
+	g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.DefaultGRPCServerFolder, consts.DefaultGRPCServerFolder+".go"))
+	if err != nil {
+		return fmt.Errorf("unable to create new generator: %w", err)
+	}
+
+	err = g.AddPackage(consts.DefaultGRPCServerFolder)
+	if err != nil {
+		return fmt.Errorf("unable to create add packages")
+	}
+
+	err = g.AddImport([]string{
+		util.MakeString("net"),
+		util.MakeString(consts.GRPCURL),
+		util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultConfigFolder)),
+		util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultProtoFolder)),
+		util.MakeString(filepath.Join(lc.Builder.ProjectName, layer.Name)),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to add imports")
+	}
+
+	serverFields := make([]system.Field, len(lc.Models))
+	serverFields = append(serverFields, system.Field{
+		Name: "Config",
+		Type: "*" + system.FieldType(consts.DefaultConfigFolder+"."+util.MakePublicName("Config")),
+	})
+	for i := range lc.Models {
+		serverFields[i] = system.Field{
+			Name: lc.Models[i].Name,
+			Type: system.FieldType(layer.Name + "." + lc.Models[i].Name),
+		}
+	}
+	serverModel := &system.Model{
+		Name:   "Server",
+		Fields: serverFields,
+	}
+	err = g.AddStruct(serverModel)
+	if err != nil {
+		return fmt.Errorf("unable to add struct Server")
+	}
+
+	err = g.AddMainRouterNewFunc(serverModel)
+	if err != nil {
+		return err
+	}
+
+	err = g.AddMainGRPCServerFunc(lc.Models)
+	if err != nil {
+		return fmt.Errorf("unable to add main grpc server func")
+	}
+
+	err = g.Close()
+	if err != nil {
+		return fmt.Errorf("unable to close grpc server generator")
+	}
+
+	return nil
+}
+
+// generateGraphQLRouter writes the schema of every model and the router serving it
+func (lc *LayerController) generateGraphQLRouter(layer *system.Layer) error {
+	queries := make([]string, 0, len(lc.Models))
+	mutations := make([]string, 0, len(lc.Models))
+	types := make([]string, 0, len(lc.Models))
+	for _, mdl := range lc.Models {
+		gql := gentags.NewGraphQL(layer, mdl)
+		queries = append(queries, gql.Queries()...)
+		mutations = append(mutations, gql.Mutations()...)
+		types = append(types, gql.SchemaTypes())
+	}
+
+	withQueries := len(queries) > 0
+	if !withQueries {
+		queries = append(queries, "ping: String!")
+	}
+
+	g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.DefaultRouterFolder, "schema.graphql"))
+	if err != nil {
+		return fmt.Errorf("unable to create new generator: %w", err)
+	}
+
+	root := "schema {\n  query: Query\n"
+	if len(mutations) > 0 {
+		root += "  mutation: Mutation\n"
+	}
+	root += "}\n\ntype Query {\n  " + strings.Join(queries, "\n  ") + "\n}\n"
+	if len(mutations) > 0 {
+		root += "\ntype Mutation {\n  " + strings.Join(mutations, "\n  ") + "\n}\n"
+	}
+
+	_, err = g.File.WriteString(root + "\n" + strings.Join(types, "\n"))
+	if err != nil {
+		return fmt.Errorf("unable to write schema: %w", err)
+	}
+
+	err = g.Close()
+	if err != nil {
+		return fmt.Errorf("unable to close schema generator")
+	}
+
+	g, err = gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.DefaultRouterFolder, consts.DefaultRouterFolder+".go"))
+	if err != nil {
+		return fmt.Errorf("unable to create new generator: %w", err)
+	}
+
+	err = g.AddPackage(consts.DefaultRouterFolder)
+	if err != nil {
+		return fmt.Errorf("unable to create add packages")
+	}
+
+	err = g.AddImport([]string{
+		"_ " + util.MakeString("embed"),
+		util.MakeString("net/http"),
+		util.MakeString(consts.GraphQLURL),
+		util.MakeString(consts.GraphQLRelayURL),
+		util.MakeString(filepath.Join(lc.Builder.ProjectName, layer.Name)),
+		util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultConfigFolder)),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to add imports")
+	}
+
+	routerFields := make([]system.Field, len(lc.Models))
+	routerFields = append(routerFields, system.Field{
+		Name: "Config",
+		Type: "*" + system.FieldType(consts.DefaultConfigFolder+"."+util.MakePublicName("Config")),
+	})
+	for i := range lc.Models {
+		routerFields[i] = system.Field{
+			Name: lc.Models[i].Name,
+			Type: system.FieldType(layer.Name + "." + lc.Models[i].Name),
+		}
+	}
+	routerModel := &system.Model{
+		Name:   "Router",
+		Fields: routerFields,
+	}
+	err = g.AddStruct(routerModel)
+	if err != nil {
+		return fmt.Errorf("unable to add struct Router")
+	}
+
+	err = g.AddMainRouterNewFunc(routerModel)
+	if err != nil {
+		return err
+	}
+
+	err = g.AddMainGraphQLRouterFunc(lc.Models, layer, withQueries)
+	if err != nil {
+		return fmt.Errorf("unable to add main graphql router func")
+	}
+
+	err = g.Close()
+	if err != nil {
+		return fmt.Errorf("unable to close router generator")
+	}
+
+	return nil
+}
+
 func (lc *LayerController) generateRouter(httpLayer *system.Layer) error {
-	err := lc.generateRouterFile(httpLayer, lc.Models)
+	err := lc.generateHTTPHelpersFile(httpLayer)
+	if err != nil {
+		return err
+	}
+
+	err = lc.generateRouterFile(httpLayer, lc.Models)
 	if err != nil {
 		return err
 	}
@@ -560,7 +1406,7 @@ func (lc *LayerController) generateRouter(httpLayer *system.Layer) error {
 }
 
 func (lc *LayerController) generateRouterFile(layer *system.Layer, mdls []*system.Model) error {
-	g, err := gen.NewGen(filepath.Join(lc.Builder.Path, consts.DefaultRouterFolder, consts.DefaultRouterFolder+".go"))
+	g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.DefaultRouterFolder, consts.DefaultRouterFolder+".go"))
 	if err != nil {
 		return fmt.Errorf("unable to create new generator: %w", err)
 	}
@@ -572,14 +1418,17 @@ func (lc *LayerController) generateRouterFile(layer *system.Layer, mdls []*syste
 		return fmt.Errorf("unable to create add packages")
 	}
 
-	err = g.AddImport([]string{
-		"_ " + util.MakeString(lc.Builder.ProjectName+"/docs"),
-		"swaggerfiles " + util.MakeString("github.com/swaggo/files"),
-		"ginSwagger " + util.MakeString("github.com/swaggo/gin-swagger"),
-		util.MakeString(consts.GinURL),
+	fw := httpfw.Select(layer.Type)
+	imports := append(fw.RouterImports(lc.Builder.ProjectName),
 		util.MakeString(filepath.Join(lc.Builder.ProjectName, layer.Name)),
 		util.MakeString(filepath.Join(lc.Builder.ProjectName, consts.DefaultConfigFolder)),
-	})
+	)
+	if lc.sharesWebhook() {
+		imports = append(imports, util.MakeString(consts.TelebotURL))
+		imports = append(imports, fw.MountImports()...)
+	}
+
+	err = g.AddImport(imports)
 	if err != nil {
 		return fmt.Errorf("unable to add imports")
 	}
@@ -589,6 +1438,12 @@ func (lc *LayerController) generateRouterFile(layer *system.Layer, mdls []*syste
 		Name: "Config",
 		Type: "*" + system.FieldType(consts.DefaultConfigFolder+"."+util.MakePublicName("Config")),
 	})
+	if lc.sharesWebhook() {
+		routerFields = append(routerFields, system.Field{
+			Name: "Webhook",
+			Type: "*telebot.Webhook",
+		})
+	}
 	for i := range mdls {
 		routerFields[i] = system.Field{
 			Name: mdls[i].Name,
@@ -609,7 +1464,7 @@ func (lc *LayerController) generateRouterFile(layer *system.Layer, mdls []*syste
 		return err
 	}
 
-	err = g.AddMainRouterFunc(lc.Models)
+	err = g.AddMainRouterFunc(lc.Models, fw, lc.sharesWebhook())
 	if err != nil {
 		return fmt.Errorf("unable to add main router file")
 	}
@@ -622,22 +1477,108 @@ func (lc *LayerController) generateRouterFile(layer *system.Layer, mdls []*syste
 	return nil
 }
 
-func (lc *LayerController) generateDefaultPostgresDockerCompose() error {
-	g, err := gen.NewGen(filepath.Join(lc.Builder.Path, "docker-compose.yaml"))
+// gormDriverURL returns the import path of the GORM driver used by the layer
+func gormDriverURL(layer *system.Layer) string {
+	switch layer.Type {
+	case consts.MySQLLayerType:
+		return consts.GormMySQLDriverURL
+	case consts.SQLiteLayerType:
+		return consts.GormSQLiteDriverURL
+	default:
+		return consts.GormPostgresDriverURL
+	}
+}
+
+// gormDSN returns the main.go statement which builds dsn from the config fields of the layer
+func gormDSN(layer *system.Layer) string {
+	cfg := consts.DefaultConfigFolder
+	switch layer.Type {
+	case consts.MySQLLayerType:
+		return fmt.Sprintf("dsn := fmt.Sprintf(\"%%s:%%s@tcp(%%s:%%s)/%%s?charset=utf8mb4&parseTime=True&loc=Local\", %s.MysqlUser, %s.MysqlPassword, %s.MysqlHost, %s.MysqlPort, %s.MysqlDb)\n",
+			cfg, cfg, cfg, cfg, cfg)
+	case consts.SQLiteLayerType:
+		return fmt.Sprintf("dsn := %s.SqlitePath\n", cfg)
+	default:
+		return fmt.Sprintf("dsn := fmt.Sprintf(\"host = %%s user = %%s password = %%s dbname = %%s port = %%s sslmode=disable\", %s.PostgresHost, %s.PostgresUser, %s.PostgresPassword, %s.PostgresDb, %s.PostgresPort)\n",
+			cfg, cfg, cfg, cfg, cfg)
+	}
+}
+
+// generateHTTPHelpersFile writes the helpers shared by the handlers of the layer if the framework needs them
+func (lc *LayerController) generateHTTPHelpersFile(layer *system.Layer) error {
+	fw := httpfw.Select(layer.Type)
+	if fw.Helpers() == "" {
+		return nil
+	}
+
+	g, err := gen.NewGen(lc.Builder.FS, layer.Path+"helpers.go")
+	if err != nil {
+		return fmt.Errorf("unable to create new generator: %w", err)
+	}
+
+	err = g.AddPackage(layer.Name)
+	if err != nil {
+		return fmt.Errorf("unable to add package %s: %w", layer.Name, err)
+	}
+
+	err = g.AddImport(fw.HelpersImports())
+	if err != nil {
+		return fmt.Errorf("unable to add imports %s: %w", layer.Name, err)
+	}
+
+	_, err = g.File.WriteString(fw.Helpers())
+	if err != nil {
+		return err
+	}
+
+	return g.Close()
+}
+
+func (lc *LayerController) generateDockerCompose() error {
+	g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, "docker-compose.yaml"))
 	if err != nil {
 		return err
 	}
 
-	_, err = g.File.WriteString(consts.DefaultPostgresDockerfile)
+	services := make([]string, 0, 2)
+	volumes := make([]string, 0, 2)
+	if lc.HavePostgres {
+		services = append(services, consts.DefaultPostgresDockerService)
+		volumes = append(volumes, consts.DefaultPostgresDockerVolume)
+	}
+	if lc.HaveMySQL {
+		services = append(services, consts.DefaultMySQLDockerService)
+		volumes = append(volumes, consts.DefaultMySQLDockerVolume)
+	}
+	if lc.HaveMongo {
+		services = append(services, consts.DefaultMongoDockerService)
+		volumes = append(volumes, consts.DefaultMongoDockerVolume)
+	}
+	if lc.HaveRedis {
+		services = append(services, consts.DefaultRedisDockerService)
+		volumes = append(volumes, consts.DefaultRedisDockerVolume)
+	}
+	if lc.HaveConsumer {
+		services = append(services, consts.DefaultNatsDockerService)
+	}
+
+	_, err = g.File.WriteString(consts.DockerComposeHeader + strings.Join(services, "\n") + "\nvolumes:\n")
 	if err != nil {
 		return err
 	}
 
+	for _, v := range volumes {
+		_, err = g.File.WriteString("  " + v + ":\n")
+		if err != nil {
+			return err
+		}
+	}
+
 	return g.Close()
 }
 
 func (lc *LayerController) generateConfigStorageFile() error {
-	g, err := gen.NewGen(filepath.Join(lc.Builder.Path, consts.DefaultConfigFolder, consts.DefaultConfigFolder+".go"))
+	g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.DefaultConfigFolder, consts.DefaultConfigFolder+".go"))
 	if err != nil {
 		return fmt.Errorf("unable to create new generator: %w", err)
 	}
@@ -647,31 +1588,36 @@ func (lc *LayerController) generateConfigStorageFile() error {
 		return fmt.Errorf("unable to add package %s: %w", consts.DefaultConfigFolder, err)
 	}
 
-	err = g.AddImport([]string{
-		util.MakeString("encoding/json"),
-		util.MakeString("io"),
-		util.MakeString("os"),
-	})
-	if err != nil {
-		return fmt.Errorf("unable to add imports %s: %w", consts.DefaultConfigFolder, err)
-	}
-
+	g.Tags = []string{lc.Builder.ConfigFormat}
 	mdlToCreate := system.Model{
 		Name: util.MakePublicName(consts.DefaultConfigFolder),
 	}
-	httpFields := addHTTPConfig(lc.Layers)
-	if len(httpFields) > 0 {
-		mdlToCreate.Fields = append(mdlToCreate.Fields, httpFields...)
+	// the fields declared in the spec follow the fields of the layers
+	configFields := append(gen.ConfigFields(lc.layerConfig()), lc.Builder.Config...)
+	imports := []string{
+		gen.ConfigImport(lc.Builder.ConfigFormat),
+		util.MakeString("errors"),
+		util.MakeString("fmt"),
+		util.MakeString("os"),
+		util.MakeString("path/filepath"),
+		util.MakeString("strings"),
 	}
-
-	postgresFields := addPostgresConfig(lc.Layers)
-	if len(postgresFields) > 0 {
-		mdlToCreate.Fields = append(mdlToCreate.Fields, postgresFields...)
+	var haveDuration, haveParsed bool
+	for _, f := range configFields {
+		mdlToCreate.Fields = append(mdlToCreate.Fields, system.Field{Name: f.Name, Type: f.GoType()})
+		haveDuration = haveDuration || f.Type == system.ConfigDuration
+		haveParsed = haveParsed || f.Type == system.ConfigInt || f.Type == system.ConfigBool
+	}
+	if haveParsed {
+		imports = append(imports, util.MakeString("strconv"))
+	}
+	if haveDuration {
+		imports = append(imports, util.MakeString("time"))
 	}
 
-	telebotFields := addTelebotConfig(lc.Layers)
-	if len(telebotFields) > 0 {
-		mdlToCreate.Fields = append(mdlToCreate.Fields, telebotFields...)
+	err = g.AddImport(imports)
+	if err != nil {
+		return fmt.Errorf("unable to add imports %s: %w", consts.DefaultConfigFolder, err)
 	}
 
 	err = g.AddStruct(&mdlToCreate)
@@ -679,86 +1625,174 @@ func (lc *LayerController) generateConfigStorageFile() error {
 		return fmt.Errorf("unable to add struct %s: %w", mdlToCreate.Name, err)
 	}
 
-	err = g.AddParseConfigMethod(&mdlToCreate)
+	err = g.AddParseConfigMethod(configFields, lc.Builder.ConfigFormat)
 	if err != nil {
 		return fmt.Errorf("unable to add parse config method %s: %w", mdlToCreate.Name, err)
 	}
 
+	err = g.AddValidateConfigMethod(configFields)
+	if err != nil {
+		return fmt.Errorf("unable to add validate config method %s: %w", mdlToCreate.Name, err)
+	}
+
+	if haveDuration {
+		err = g.AddConfigDurationType()
+		if err != nil {
+			return fmt.Errorf("unable to add duration type %s: %w", mdlToCreate.Name, err)
+		}
+	}
+
 	err = g.Close()
 	if err != nil {
 		return fmt.Errorf("unable to close file %s: %w", mdlToCreate.Name, err)
 	}
 
-	g, err = gen.NewGen(filepath.Join(lc.Builder.Path, consts.DefaultConfigFolder+".json"))
+	configFile := gen.ConfigFile(lc.Builder.ConfigFormat)
+	g, err = gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, configFile))
 	if err != nil {
 		return fmt.Errorf("unable to create new generator: %w", err)
 	}
 
-	err = g.WriteJSON(&mdlToCreate)
+	err = g.WriteConfig(configFields, lc.Builder.ConfigFormat)
 	if err != nil {
-		return fmt.Errorf("unable to write json %s: %w", mdlToCreate.Name, err)
+		return fmt.Errorf("unable to write %s: %w", configFile, err)
 	}
 
-	return nil
-}
+	err = g.Close()
+	if err != nil {
+		return fmt.Errorf("unable to close file %s: %w", configFile, err)
+	}
 
-func addHTTPConfig(layers []*system.Layer) []system.Field {
-	for _, layer := range layers {
-		if layer.Type == consts.HTTPLayerType {
-			return []system.Field{
-				{
-					Name: "HttpHost",
-					Type: "string",
-				},
-				{
-					Name: "HttpPort",
-					Type: "string",
-				},
-			}
+	envs := make([]string, 0, len(lc.Builder.Environments))
+	for env := range lc.Builder.Environments {
+		envs = append(envs, env)
+	}
+	slices.Sort(envs)
+	for _, env := range envs {
+		err = lc.generateConfigProfileFile(configFields, env)
+		if err != nil {
+			return err
 		}
 	}
-	return nil
+
+	g, err = gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, consts.EnvExampleFile))
+	if err != nil {
+		return fmt.Errorf("unable to create new generator: %w", err)
+	}
+
+	err = g.WriteEnv(configFields)
+	if err != nil {
+		return fmt.Errorf("unable to write env %s: %w", mdlToCreate.Name, err)
+	}
+
+	return g.Close()
+}
+
+// generateConfigProfileFile writes the overrides of the environment, e.g. config.prod.json
+func (lc *LayerController) generateConfigProfileFile(fields []system.ConfigField, env string) error {
+	profileFields, err := gen.ProfileFields(fields, env, lc.Builder.Environments[env])
+	if err != nil {
+		return err
+	}
+
+	profileFile := gen.ConfigProfileFile(lc.Builder.ConfigFormat, env)
+	g, err := gen.NewGen(lc.Builder.FS, filepath.Join(lc.Builder.Path, profileFile))
+	if err != nil {
+		return fmt.Errorf("unable to create new generator: %w", err)
+	}
+
+	err = g.WriteConfig(profileFields, lc.Builder.ConfigFormat)
+	if err != nil {
+		return fmt.Errorf("unable to write %s: %w", profileFile, err)
+	}
+
+	return g.Close()
+}
+
+// layerConfigs are the config fields of the layer types in the order they are written
+var layerConfigs = []struct {
+	types  []string
+	fields []string
+}{
+	{
+		types:  []string{consts.HTTPLayerType, consts.NetHTTPLayerType, consts.EchoLayerType, consts.FiberLayerType, consts.GraphQLLayerType},
+		fields: []string{"HttpHost", "HttpPort"},
+	},
+	{
+		types:  []string{consts.RepoLayerType},
+		fields: []string{"PostgresHost", "PostgresPort", "PostgresDb", "PostgresUser", "PostgresPassword"},
+	},
+	{
+		types:  []string{consts.MySQLLayerType},
+		fields: []string{"MysqlHost", "MysqlPort", "MysqlDb", "MysqlUser", "MysqlPassword"},
+	},
+	// ":memory:" keeps the sqlite database in memory
+	{
+		types:  []string{consts.SQLiteLayerType},
+		fields: []string{"SqlitePath"},
+	},
+	{
+		types:  []string{consts.MongoLayerType},
+		fields: []string{"MongoUri", "MongoDb"},
+	},
+	{
+		types:  []string{consts.GRPCLayerType},
+		fields: []string{"GrpcHost", "GrpcPort"},
+	},
+	// the ttl of the cached reads, e.g. 5m
+	{
+		types:  []string{consts.RedisLayerType},
+		fields: []string{"RedisAddr", "RedisPassword", "RedisTtl"},
+	},
+	// the broker type (nats | rabbitmq | memory), comma separated broker urls and the topic prefix
+	{
+		types:  []string{consts.ConsumerLayerType},
+		fields: []string{"BrokerType", "BrokerUrls", "BrokerTopicPrefix"},
+	},
+	// the allowlist holds the comma separated user or chat ids let in
+	{
+		types:  []string{consts.TelebotLayerType},
+		fields: []string{"TelebotToken", "TelebotAllowlist"},
+	},
+	{
+		types:  []string{consts.DiscordLayerType},
+		fields: []string{"DiscordToken", "DiscordGuildId"},
+	},
+	{
+		types:  []string{consts.SlackLayerType},
+		fields: []string{"SlackBotToken", "SlackAppToken"},
+	},
 }
 
-func addPostgresConfig(layers []*system.Layer) []system.Field {
-	for _, layer := range layers {
-		if layer.Type == consts.RepoLayerType {
-			return []system.Field{
-				{
-					Name: "PostgresHost",
-					Type: "string",
-				},
-				{
-					Name: "PostgresPort",
-					Type: "string",
-				},
-				{
-					Name: "PostgresDb",
-					Type: "string",
-				},
-				{
-					Name: "PostgresUser",
-					Type: "string",
-				},
-				{
-					Name: "PostgresPassword",
-					Type: "string",
-				}}
+// layerConfig are the names of the config fields of the layers, each layer type adds its fields once
+func (lc *LayerController) layerConfig() []string {
+	var fields []string
+	for _, config := range layerConfigs {
+		if slices.ContainsFunc(lc.Layers, func(layer *system.Layer) bool {
+			return slices.Contains(config.types, layer.Type)
+		}) {
+			fields = append(fields, config.fields...)
 		}
 	}
-	return nil
+
+	return append(fields, lc.telebotConfig()...)
 }
 
-func addTelebotConfig(layers []*system.Layer) []system.Field {
-	for _, layer := range layers {
-		if layer.Type == consts.RepoLayerType {
-			return []system.Field{
-				{
-					Name: "TelebotToken",
-					Type: "string",
-				},
-			}
+// telebotConfig adds the poller timeout,
+// or the webhook url with its own listener and TLS when it is not shared
+func (lc *LayerController) telebotConfig() []string {
+	for _, layer := range lc.Layers {
+		if layer.Type != consts.TelebotLayerType {
+			continue
+		}
+
+		if layer.Poller != consts.TelebotWebhookPoller {
+			return []string{"TelebotPollerTimeout"}
+		}
+		if lc.sharesWebhook() {
+			return []string{"TelebotWebhookUrl"}
 		}
+		return []string{"TelebotWebhookUrl", "TelebotWebhookListen", "TelebotWebhookTlsCert", "TelebotWebhookTlsKey"}
 	}
 	return nil
 }
@@ -0,0 +1,287 @@
+package builder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"gowizard/builder/fsys"
+	"gowizard/builder/gen"
+	"gowizard/consts"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Resolution is the way a file edited since the last generation is written
+type Resolution string
+
+const (
+	ResolveSkip      Resolution = "skip"
+	ResolveOverwrite Resolution = "overwrite"
+	// ResolveNew writes the generated file next to the edited one with the consts.LockNewSuffix
+	ResolveNew Resolution = "new"
+)
+
+// Lock is the manifest of the last generation, the hash of the spec and the hash of each file by
+// its path in the project, the spec is left empty while a conflict is left to settle
+type Lock struct {
+	Spec  string            `json:"spec"`
+	Files map[string]string `json:"files"`
+}
+
+func readLock(fs fsys.FS, path string) (*Lock, error) {
+	lock := &Lock{Files: make(map[string]string)}
+	data, err := fs.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+
+	err = json.Unmarshal(data, lock)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	if lock.Files == nil {
+		lock.Files = make(map[string]string)
+	}
+
+	return lock, nil
+}
+
+func (l *Lock) write(fs fsys.FS, path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal lock: %w", err)
+	}
+
+	return writeFile(fs, path, append(data, '\n'))
+}
+
+// lockAction is what a generation does with a file found on the disk
+type lockAction int
+
+const (
+	// lockKeep leaves the file as it is, its inputs didn't change or it is up to date
+	lockKeep lockAction = iota
+	// lockWrite replaces the file, it is not edited or only in the protected regions carried over
+	lockWrite
+	// lockConflict asks how to write the file, it is edited since the last generation
+	lockConflict
+)
+
+// action compares the generated file with the one on the disk and the last generated one
+func (l *Lock) action(rel string, generated, current []byte) lockAction {
+	last, known := l.Files[filepath.ToSlash(rel)]
+	hash, currentHash := fileHash(rel, generated), fileHash(rel, current)
+	switch {
+	case known && hash == last, currentHash == hash:
+		return lockKeep
+	case known && currentHash == last:
+		return lockWrite
+	default:
+		return lockConflict
+	}
+}
+
+// fileHash is the hash of the file as go fmt leaves it, the bodies of the protected regions are
+// left out, so editing them is not a change of the file
+func fileHash(path string, data []byte) string {
+	sum := sha256.Sum256(gen.StripRegions(formatFile(path, data)))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatFile formats a go file as go fmt does after a generation, the other files are left as they are
+func formatFile(path string, data []byte) []byte {
+	if filepath.Ext(path) != ".go" {
+		return data
+	}
+
+	formatted, err := format.Source(data)
+	if err != nil {
+		return data
+	}
+
+	return formatted
+}
+
+// lockFS writes the generated files over the disk against the lock of the last generation:
+// a file generated as last time is left as it is, a file edited since the last generation
+// outside the protected regions is a conflict settled by resolve
+type lockFS struct {
+	fsys.FS
+	root    string
+	last    *Lock
+	next    *Lock
+	resolve func(path string) (Resolution, error)
+	// written are the files written in this generation, a file may be generated twice
+	written map[string]bool
+	// report tells what happened to the conflicting and the no longer generated files
+	report []string
+}
+
+var _ fsys.FS = &lockFS{}
+
+func newLockFS(fs fsys.FS, root, spec string, resolve func(path string) (Resolution, error)) (*lockFS, error) {
+	last, err := readLock(fs, filepath.Join(root, consts.LockFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return &lockFS{
+		FS:      fs,
+		root:    root,
+		last:    last,
+		next:    &Lock{Spec: spec, Files: make(map[string]string)},
+		resolve: resolve,
+		written: make(map[string]bool),
+	}, nil
+}
+
+func (l *lockFS) Create(path string) (fsys.File, error) {
+	return &lockFile{path: path, fs: l}, nil
+}
+
+type lockFile struct {
+	bytes.Buffer
+	path string
+	fs   *lockFS
+}
+
+func (f *lockFile) Close() error {
+	return f.fs.write(f.path, f.Bytes())
+}
+
+func (l *lockFS) write(path string, data []byte) error {
+	rel, err := filepath.Rel(l.root, path)
+	if err != nil {
+		return fmt.Errorf("unable to locate %s in the project: %w", path, err)
+	}
+
+	l.next.Files[filepath.ToSlash(rel)] = fileHash(path, data)
+
+	current, err := l.FS.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || l.written[path] {
+		l.written[path] = true
+		return writeFile(l.FS, path, data)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", path, err)
+	}
+
+	switch l.last.action(rel, data, current) {
+	case lockKeep:
+		return nil
+	case lockWrite:
+		l.written[path] = true
+		return writeFile(l.FS, path, data)
+	}
+
+	resolution := ResolveSkip
+	if l.resolve != nil {
+		resolution, err = l.resolve(rel)
+		if err != nil {
+			return fmt.Errorf("unable to resolve conflict of %s: %w", rel, err)
+		}
+	}
+
+	if resolution == ResolveOverwrite {
+		l.report = append(l.report, fmt.Sprintf("overwritten %s, it was edited since the last generation", rel))
+		l.written[path] = true
+		return writeFile(l.FS, path, data)
+	}
+
+	// the file on the disk is not the generated one, the lock keeps the last generation and no
+	// spec, so the conflict is raised again by the next one until the file is merged or overwritten
+	l.next.Spec = ""
+	if last, ok := l.last.Files[filepath.ToSlash(rel)]; ok {
+		l.next.Files[filepath.ToSlash(rel)] = last
+	} else {
+		delete(l.next.Files, filepath.ToSlash(rel))
+	}
+
+	if resolution == ResolveNew {
+		l.report = append(l.report, fmt.Sprintf("written %s%s, %s was edited since the last generation", rel, consts.LockNewSuffix, rel))
+		return writeFile(l.FS, path+consts.LockNewSuffix, data)
+	}
+
+	l.report = append(l.report, fmt.Sprintf("skipped %s, it was edited since the last generation", rel))
+	return nil
+}
+
+// unchanged reports whether the spec is the one of the last generation and every file it generated
+// is on the disk, the generation would write nothing new, so the project is left as it is
+func (l *lockFS) unchanged() (bool, error) {
+	if l.last.Spec == "" || l.last.Spec != l.next.Spec {
+		return false, nil
+	}
+
+	for rel := range l.last.Files {
+		path := filepath.Join(l.root, filepath.FromSlash(rel))
+		_, err := l.FS.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("unable to read %s: %w", path, err)
+		}
+	}
+
+	return true, nil
+}
+
+// removeStale removes the files of the last generation no longer generated, the edited ones are reported
+func (l *lockFS) removeStale() error {
+	var stale []string
+	for path := range l.last.Files {
+		if _, ok := l.next.Files[path]; !ok {
+			stale = append(stale, path)
+		}
+	}
+	slices.Sort(stale)
+
+	for _, rel := range stale {
+		path := filepath.Join(l.root, filepath.FromSlash(rel))
+		current, err := l.FS.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return fmt.Errorf("unable to read %s: %w", path, err)
+		case fileHash(path, current) == l.last.Files[rel] && !gen.HasEditedRegions(current):
+			err = l.FS.Remove(path)
+			if err != nil {
+				return fmt.Errorf("unable to remove %s: %w", path, err)
+			}
+			l.report = append(l.report, fmt.Sprintf("removed %s, it is no longer generated", rel))
+		default:
+			l.report = append(l.report, fmt.Sprintf("%s is no longer generated but was edited, remove it if it is not used", rel))
+		}
+	}
+
+	return nil
+}
+
+// save writes the lock of this generation
+func (l *lockFS) save() error {
+	return l.next.write(l.FS, filepath.Join(l.root, consts.LockFile))
+}
+
+func writeFile(fs fsys.FS, path string, data []byte) error {
+	f, err := fs.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", path, err)
+	}
+
+	_, err = f.Write(data)
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+
+	return f.Close()
+}
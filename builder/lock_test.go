@@ -0,0 +1,228 @@
+package builder
+
+import (
+	"gowizard/builder/fsys"
+	"gowizard/builder/gen"
+	"gowizard/consts"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	lockGenerated = "package service\n\nfunc f() error {\n\treturn nil\n}\n"
+	lockNext      = "package service\n\nfunc f() error {\n\treturn errors.ErrUnsupported\n}\n"
+	lockEdited    = "package service\n\nfunc f() error {\n\treturn nil\n}\n\nfunc g() {}\n"
+)
+
+func TestLockAction(t *testing.T) {
+	tests := []struct {
+		name      string
+		known     bool
+		generated string
+		current   string
+		want      lockAction
+	}{
+		{name: "inputs unchanged", known: true, generated: lockGenerated, current: lockEdited, want: lockKeep},
+		{name: "up to date", known: true, generated: lockNext, current: lockNext, want: lockKeep},
+		{name: "up to date unknown", generated: lockNext, current: lockNext, want: lockKeep},
+		{name: "not edited", known: true, generated: lockNext, current: lockGenerated, want: lockWrite},
+		{name: "formatted", known: true, generated: lockNext, current: strings.ReplaceAll(lockGenerated, "\t", "  "), want: lockWrite},
+		{name: "edited", known: true, generated: lockNext, current: lockEdited, want: lockConflict},
+		{name: "unknown", generated: lockNext, current: lockGenerated, want: lockConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock := &Lock{Files: make(map[string]string)}
+			if tt.known {
+				lock.Files["service/f.go"] = fileHash("f.go", []byte(lockGenerated))
+			}
+
+			got := lock.action(filepath.Join("service", "f.go"), []byte(tt.generated), []byte(tt.current))
+			if got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileHashRegions(t *testing.T) {
+	src := "package service\n\nfunc f() error {\n" + gen.Region("F", "return nil\n") + "}\n"
+	edited := strings.Replace(src, "return nil", "return errors.ErrUnsupported", 1)
+	if fileHash("f.go", []byte(src)) != fileHash("f.go", []byte(edited)) {
+		t.Fatal("an edited region body changes the hash")
+	}
+
+	edited = strings.Replace(src, "func f()", "func g()", 1)
+	if fileHash("f.go", []byte(src)) == fileHash("f.go", []byte(edited)) {
+		t.Fatal("an edit outside the regions keeps the hash")
+	}
+}
+
+func TestLockFSResolve(t *testing.T) {
+	tests := []struct {
+		resolution Resolution
+		wantFile   string
+		wantNew    bool
+		wantHash   string
+		wantSpec   string
+	}{
+		{resolution: ResolveSkip, wantFile: lockEdited, wantHash: lockGenerated},
+		{resolution: ResolveNew, wantFile: lockEdited, wantNew: true, wantHash: lockGenerated},
+		{resolution: ResolveOverwrite, wantFile: lockNext, wantHash: lockNext, wantSpec: "spec"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.resolution), func(t *testing.T) {
+			fs := fsys.NewMemory()
+			root := t.TempDir()
+			path := filepath.Join(root, "service", "f.go")
+			last := &Lock{Files: map[string]string{"service/f.go": fileHash(path, []byte(lockGenerated))}}
+			err := last.write(fs, filepath.Join(root, consts.LockFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = writeFile(fs, path, []byte(lockEdited))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var resolved []string
+			lfs, err := newLockFS(fs, root, "spec", func(rel string) (Resolution, error) {
+				resolved = append(resolved, rel)
+				return tt.resolution, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = writeFile(lfs, path, []byte(lockNext))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = lfs.save()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(resolved) != 1 || resolved[0] != filepath.Join("service", "f.go") {
+				t.Fatalf("got resolved %v, want the conflict of service/f.go", resolved)
+			}
+
+			current, err := fs.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(current) != tt.wantFile {
+				t.Fatalf("got file\n%s\nwant\n%s", current, tt.wantFile)
+			}
+
+			_, err = fs.ReadFile(path + consts.LockNewSuffix)
+			if (err == nil) != tt.wantNew {
+				t.Fatalf("got new file %v, want %v", err == nil, tt.wantNew)
+			}
+
+			lock, err := readLock(fs, filepath.Join(root, consts.LockFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lock.Files["service/f.go"] != fileHash(path, []byte(tt.wantHash)) {
+				t.Fatalf("the lock doesn't hold the hash of\n%s", tt.wantHash)
+			}
+			if lock.Spec != tt.wantSpec {
+				t.Fatalf("got spec %q, want %q", lock.Spec, tt.wantSpec)
+			}
+		})
+	}
+}
+
+func TestLockFSUnchanged(t *testing.T) {
+	tests := []struct {
+		name     string
+		lastSpec string
+		spec     string
+		missing  bool
+		want     bool
+	}{
+		{name: "unchanged", lastSpec: "spec", spec: "spec", want: true},
+		{name: "spec changed", lastSpec: "spec", spec: "next", want: false},
+		{name: "conflict left", lastSpec: "", spec: "", want: false},
+		{name: "file removed", lastSpec: "spec", spec: "spec", missing: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := fsys.NewMemory()
+			root := t.TempDir()
+			path := filepath.Join(root, "service", "f.go")
+			last := &Lock{Spec: tt.lastSpec, Files: map[string]string{"service/f.go": fileHash(path, []byte(lockGenerated))}}
+			err := last.write(fs, filepath.Join(root, consts.LockFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.missing {
+				err = writeFile(fs, path, []byte(lockGenerated))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			lfs, err := newLockFS(fs, root, tt.spec, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := lfs.unchanged()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got unchanged %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockFSRemoveStale(t *testing.T) {
+	fs := fsys.NewMemory()
+	root := t.TempDir()
+	generated, edited := filepath.Join(root, "service", "a.go"), filepath.Join(root, "service", "b.go")
+	last := &Lock{Files: map[string]string{
+		"service/a.go": fileHash(generated, []byte(lockGenerated)),
+		"service/b.go": fileHash(edited, []byte(lockGenerated)),
+	}}
+	err := last.write(fs, filepath.Join(root, consts.LockFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writeFile(fs, generated, []byte(lockGenerated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writeFile(fs, edited, []byte(lockEdited))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lfs, err := newLockFS(fs, root, "spec", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = lfs.removeStale()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = fs.ReadFile(generated); err == nil {
+		t.Fatal("the stale generated file is not removed")
+	}
+	if _, err = fs.ReadFile(edited); err != nil {
+		t.Fatal("the stale edited file is removed")
+	}
+	want := []string{
+		"removed service/a.go, it is no longer generated",
+		"service/b.go is no longer generated but was edited, remove it if it is not used",
+	}
+	if strings.Join(lfs.report, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got report %v, want %v", lfs.report, want)
+	}
+}
@@ -0,0 +1,150 @@
+package chatfw
+
+import (
+	"fmt"
+	"gowizard/consts"
+	"gowizard/util"
+	"strings"
+)
+
+// Discord registers the commands as application commands with a single string option, the payload
+type Discord struct{}
+
+func (*Discord) Args() []string {
+	return []string{"ctx", consts.DefaultChatFolder + ".Context"}
+}
+
+func (*Discord) Returns() []string {
+	return []string{"error"}
+}
+
+func (*Discord) Imports(projectName string) []string {
+	return []string{util.MakeString(projectName + "/" + consts.DefaultChatFolder)}
+}
+
+func (*Discord) Payload() string {
+	return "ctx.Payload()"
+}
+
+func (*Discord) Reply(text string) string {
+	return fmt.Sprintf("return ctx.Reply(%s)\n", text)
+}
+
+func (*Discord) Folder() string {
+	return consts.DefaultDiscordRouterFolder
+}
+
+func (*Discord) RouterName() string {
+	return "DiscordRouter"
+}
+
+func (*Discord) Client() string {
+	return "*discordgo.Session"
+}
+
+func (*Discord) RouterImports(projectName string) []string {
+	return []string{
+		util.MakeString("os"),
+		util.MakeString("os/signal"),
+		util.MakeString(consts.DiscordURL),
+		util.MakeString(projectName + "/" + consts.DefaultChatFolder),
+	}
+}
+
+func (*Discord) RouterRun(commands []Command) string {
+	var sb strings.Builder
+	sb.WriteString("handlers := map[string]func(c chat.Context) error{\n")
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf("%s: %s,\n", util.MakeString(c.Name), c.Handler))
+	}
+	sb.WriteString(`}
+
+r.Bot.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+if i.Type != discordgo.InteractionApplicationCommand {
+return
+}
+
+handler, ok := handlers[i.ApplicationCommandData().Name]
+if ok {
+_ = handler(chat.NewDiscordContext(s, i))
+}
+})
+
+err := r.Bot.Open()
+if err != nil {
+panic(err.Error())
+}
+defer r.Bot.Close()
+
+`)
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf(`_, err = r.Bot.ApplicationCommandCreate(r.Bot.State.User.ID, r.Config.DiscordGuildId, &discordgo.ApplicationCommand{
+Name: %s,
+Description: %s,
+Options: []*discordgo.ApplicationCommandOption{{
+Type: discordgo.ApplicationCommandOptionString,
+Name: "payload",
+Description: "JSON payload",
+}},
+})
+if err != nil {
+panic(err.Error())
+}
+`, util.MakeString(c.Name), util.MakeString(c.Description)))
+	}
+	sb.WriteString(`
+stop := make(chan os.Signal, 1)
+signal.Notify(stop, os.Interrupt)
+<-stop
+`)
+
+	return sb.String()
+}
+
+func (*Discord) MainImports() []string {
+	return []string{util.MakeString(consts.DiscordURL)}
+}
+
+func (*Discord) MainClient(config string) string {
+	return fmt.Sprintf(`bot, err := discordgo.New("Bot " + %s.DiscordToken)
+if err != nil {
+panic(err.Error())
+}
+
+`, config)
+}
+
+func (*Discord) Sources(string) []File {
+	return []File{
+		{
+			Name:    "discord",
+			Imports: []string{util.MakeString(consts.DiscordURL)},
+			Source: `type discordContext struct {
+	s *discordgo.Session
+	i *discordgo.InteractionCreate
+}
+
+func NewDiscordContext(s *discordgo.Session, i *discordgo.InteractionCreate) Context {
+	return &discordContext{s: s, i: i}
+}
+
+func (c *discordContext) Payload() string {
+	for _, opt := range c.i.ApplicationCommandData().Options {
+		if opt.Name == "payload" {
+			return opt.StringValue()
+		}
+	}
+
+	return ""
+}
+
+func (c *discordContext) Reply(text string) error {
+	return c.s.InteractionRespond(c.i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: text},
+	})
+}
+`,
+		},
+	}
+}
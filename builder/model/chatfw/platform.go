@@ -0,0 +1,96 @@
+package chatfw
+
+import (
+	"gowizard/consts"
+)
+
+// Platform describes how the generated chat controller layer and its router talk to a chat platform.
+// The handlers share the command logic of gentags.Chat, only reading the payload and replying differ.
+// Imports are returned ready to be written, quoted and aliased when needed.
+type Platform interface {
+	// Args are the name and type pairs of a handler
+	Args() []string
+	// Returns are the results of a handler
+	Returns() []string
+	// Imports are used by the controller layer files
+	Imports(projectName string) []string
+	// Payload is the expression of the text following the command
+	Payload() string
+	// Reply sends text to the chat and leaves the handler
+	Reply(text string) string
+
+	// Folder is the package of the router
+	Folder() string
+	RouterName() string
+	// Client is the type of the platform client kept by the router
+	Client() string
+	RouterImports(projectName string) []string
+	// RouterRun registers the commands and starts listening
+	RouterRun(commands []Command) string
+
+	MainImports() []string
+	// MainClient creates the bot client in main.go
+	MainClient(config string) string
+
+	// Sources are the platform files of the generated chat package
+	Sources(projectName string) []File
+}
+
+// Command is a slash command, Name has no leading slash, e.g. usercreate
+type Command struct {
+	Name        string
+	Description string
+	Handler     string
+}
+
+// File is a file of the generated chat package
+type File struct {
+	Name    string
+	Imports []string
+	Source  string
+}
+
+// Select returns the platform of the layer type or nil if the layer is not a chat controller
+func Select(layerType string) Platform {
+	switch layerType {
+	case consts.TelebotLayerType:
+		return &Telebot{}
+	case consts.DiscordLayerType:
+		return &Discord{}
+	case consts.SlackLayerType:
+		return &Slack{}
+	default:
+		return nil
+	}
+}
+
+var _ Platform = &Telebot{}
+var _ Platform = &Discord{}
+var _ Platform = &Slack{}
+
+// ContextSource is the platform independent part of the chat package, it is used by discord and slack handlers
+const ContextSource = `// Context is a slash command call: the text following the command and the way to answer it
+type Context interface {
+	Payload() string
+	Reply(text string) error
+}
+
+// Fake is a Context for handler tests, the replies are recorded
+type Fake struct {
+	Text    string
+	Replies []string
+}
+
+func NewFake(payload string) *Fake {
+	return &Fake{Text: payload}
+}
+
+func (f *Fake) Payload() string {
+	return f.Text
+}
+
+func (f *Fake) Reply(text string) error {
+	f.Replies = append(f.Replies, text)
+	return nil
+}
+`
@@ -0,0 +1,130 @@
+package chatfw
+
+import (
+	"fmt"
+	"gowizard/consts"
+	"gowizard/util"
+	"strings"
+)
+
+// Slack receives the slash commands over socket mode, they are declared in the slack app settings
+type Slack struct{}
+
+func (*Slack) Args() []string {
+	return []string{"ctx", consts.DefaultChatFolder + ".Context"}
+}
+
+func (*Slack) Returns() []string {
+	return []string{"error"}
+}
+
+func (*Slack) Imports(projectName string) []string {
+	return []string{util.MakeString(projectName + "/" + consts.DefaultChatFolder)}
+}
+
+func (*Slack) Payload() string {
+	return "ctx.Payload()"
+}
+
+func (*Slack) Reply(text string) string {
+	return fmt.Sprintf("return ctx.Reply(%s)\n", text)
+}
+
+func (*Slack) Folder() string {
+	return consts.DefaultSlackRouterFolder
+}
+
+func (*Slack) RouterName() string {
+	return "SlackRouter"
+}
+
+func (*Slack) Client() string {
+	return "*socketmode.Client"
+}
+
+func (*Slack) RouterImports(projectName string) []string {
+	return []string{
+		util.MakeString(consts.SlackURL),
+		util.MakeString(consts.SlackSocketModeURL),
+		util.MakeString(projectName + "/" + consts.DefaultChatFolder),
+	}
+}
+
+func (*Slack) RouterRun(commands []Command) string {
+	var sb strings.Builder
+	sb.WriteString("handlers := map[string]func(c chat.Context) error{\n")
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf("%s: %s,\n", util.MakeString("/"+c.Name), c.Handler))
+	}
+	sb.WriteString(`}
+
+go func() {
+for evt := range r.Bot.Events {
+if evt.Type != socketmode.EventTypeSlashCommand {
+continue
+}
+
+cmd, ok := evt.Data.(slack.SlashCommand)
+if !ok {
+continue
+}
+r.Bot.Ack(*evt.Request)
+
+handler, ok := handlers[cmd.Command]
+if ok {
+_ = handler(chat.NewSlackContext(r.Bot, cmd))
+}
+}
+}()
+
+err := r.Bot.Run()
+if err != nil {
+panic(err.Error())
+}
+`)
+
+	return sb.String()
+}
+
+func (*Slack) MainImports() []string {
+	return []string{
+		util.MakeString(consts.SlackURL),
+		util.MakeString(consts.SlackSocketModeURL),
+	}
+}
+
+func (*Slack) MainClient(config string) string {
+	return fmt.Sprintf(`bot := socketmode.New(slack.New(%s.SlackBotToken, slack.OptionAppLevelToken(%s.SlackAppToken)))
+
+`, config, config)
+}
+
+func (*Slack) Sources(string) []File {
+	return []File{
+		{
+			Name: "slack",
+			Imports: []string{
+				util.MakeString(consts.SlackURL),
+				util.MakeString(consts.SlackSocketModeURL),
+			},
+			Source: `type slackContext struct {
+	client *socketmode.Client
+	cmd    slack.SlashCommand
+}
+
+func NewSlackContext(client *socketmode.Client, cmd slack.SlashCommand) Context {
+	return &slackContext{client: client, cmd: cmd}
+}
+
+func (c *slackContext) Payload() string {
+	return c.cmd.Text
+}
+
+func (c *slackContext) Reply(text string) error {
+	_, _, err := c.client.PostMessage(c.cmd.ChannelID, slack.MsgOptionText(text, false))
+	return err
+}
+`,
+		},
+	}
+}
@@ -0,0 +1,541 @@
+package chatfw
+
+import (
+	"fmt"
+	"gowizard/consts"
+	"gowizard/util"
+	"path/filepath"
+	"strings"
+)
+
+// Telebot gets updates by long polling unless Webhook is set,
+// Shared webhooks are served by the http router instead of their own listener
+type Telebot struct {
+	Webhook bool
+	Shared  bool
+}
+
+func (*Telebot) Args() []string {
+	return []string{"ctx", "telebot.Context"}
+}
+
+func (*Telebot) Returns() []string {
+	return []string{"error"}
+}
+
+func (*Telebot) Imports(string) []string {
+	return []string{util.MakeString(consts.TelebotURL)}
+}
+
+func (*Telebot) Payload() string {
+	return "ctx.Message().Payload"
+}
+
+func (*Telebot) Reply(text string) string {
+	return fmt.Sprintf("return ctx.Send(%s)\n", text)
+}
+
+func (*Telebot) Folder() string {
+	return consts.DefaultTelerouterFolder
+}
+
+func (*Telebot) RouterName() string {
+	return "TeleRouter"
+}
+
+func (*Telebot) Client() string {
+	return "*telebot.Bot"
+}
+
+func (*Telebot) RouterImports(projectName string) []string {
+	return []string{
+		util.MakeString(consts.TelebotURL),
+		util.MakeString(filepath.Join(projectName, consts.DefaultChatFolder)),
+	}
+}
+
+// RouterRun guards every handler with the allowlist, publishes the command menu and registers /help
+// next to the commands and the conversation handlers
+func (*Telebot) RouterRun(commands []Command) string {
+	var sb strings.Builder
+	sb.WriteString(`allow, err := chat.Allow(r.Config.TelebotAllowlist)
+if err != nil {
+panic(err.Error())
+}
+r.Bot.Use(allow)
+
+commands := []telebot.Command{
+`)
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf("{Text: %s, Description: %s},\n", util.MakeString(c.Name), util.MakeString(c.Description)))
+	}
+	sb.WriteString(`{Text: "help", Description: "List the commands"},
+{Text: "cancel", Description: "Stop answering the questions"},
+}
+err = r.Bot.SetCommands(commands)
+if err != nil {
+panic(err.Error())
+}
+
+r.Bot.Handle("/help", chat.Help(commands))
+`)
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf("r.Bot.Handle(%s, %s)\n", util.MakeString("/"+c.Name), c.Handler))
+	}
+	sb.WriteString(`r.Bot.Handle("/cancel", chat.Cancel)
+r.Bot.Handle(telebot.OnText, chat.Answer)
+r.Bot.Handle(&chat.BtnPrev, chat.Prev)
+r.Bot.Handle(&chat.BtnNext, chat.Next)
+r.Bot.Handle(&chat.BtnDelete, chat.Delete)
+`)
+	sb.WriteString("\nr.Bot.Start()\n")
+
+	return sb.String()
+}
+
+func (t *Telebot) MainImports() []string {
+	return []string{util.MakeString(consts.TelebotURL)}
+}
+
+func (t *Telebot) MainClient(config string) string {
+	var sb strings.Builder
+	poller := fmt.Sprintf("&telebot.LongPoller{Timeout: %s.TelebotPollerTimeout.Duration}", config)
+	switch {
+	case t.Webhook && t.Shared:
+		sb.WriteString(fmt.Sprintf(`webhook := &telebot.Webhook{
+Endpoint: &telebot.WebhookEndpoint{PublicURL: %[1]s.TelebotWebhookUrl},
+}
+
+`, config))
+		poller = "webhook"
+	case t.Webhook:
+		sb.WriteString(fmt.Sprintf(`webhook := &telebot.Webhook{
+Listen: %[1]s.TelebotWebhookListen,
+Endpoint: &telebot.WebhookEndpoint{PublicURL: %[1]s.TelebotWebhookUrl},
+}
+if %[1]s.TelebotWebhookTlsCert != "" {
+webhook.TLS = &telebot.WebhookTLS{Cert: %[1]s.TelebotWebhookTlsCert, Key: %[1]s.TelebotWebhookTlsKey}
+}
+
+`, config))
+		poller = "webhook"
+	}
+
+	sb.WriteString(fmt.Sprintf(`bot, err := telebot.NewBot(telebot.Settings{
+Token: %s.TelebotToken,
+Poller: %s,
+})
+if err != nil {
+panic(err.Error())
+}
+
+`, config, poller))
+
+	return sb.String()
+}
+
+func (*Telebot) Sources(string) []File {
+	return []File{
+		{
+			Name: "conversation",
+			Imports: []string{
+				util.MakeString("encoding/json"),
+				util.MakeString("errors"),
+				util.MakeString("fmt"),
+				util.MakeString("strconv"),
+				util.MakeString("strings"),
+				util.MakeString("sync"),
+				util.MakeString(consts.TelebotURL),
+			},
+			Source: conversationSource,
+		},
+		{
+			Name: "menu",
+			Imports: []string{
+				util.MakeString("fmt"),
+				util.MakeString("log"),
+				util.MakeString("strconv"),
+				util.MakeString("strings"),
+				util.MakeString(consts.TelebotURL),
+			},
+			Source: menuSource,
+		},
+		{
+			Name:    "telebot",
+			Imports: []string{util.MakeString(consts.TelebotURL)},
+			Source: `// FakeTelebot is a telebot.Context for handler tests, only the message, sending and editing
+// methods are implemented and the sent values are recorded, other methods panic
+type FakeTelebot struct {
+	telebot.Context
+	Msg  *telebot.Message
+	Sent []interface{}
+}
+
+func NewFakeTelebot(payload string) *FakeTelebot {
+	return &FakeTelebot{Msg: &telebot.Message{
+		Payload: payload,
+		Chat:    &telebot.Chat{ID: 1},
+		Sender:  &telebot.User{ID: 1},
+	}}
+}
+
+// Type sets the text of the next message sent by the user
+func (f *FakeTelebot) Type(text string) *FakeTelebot {
+	f.Msg = &telebot.Message{Text: text, Chat: f.Msg.Chat, Sender: f.Msg.Sender}
+	return f
+}
+
+func (f *FakeTelebot) Message() *telebot.Message {
+	return f.Msg
+}
+
+func (f *FakeTelebot) Chat() *telebot.Chat {
+	return f.Msg.Chat
+}
+
+func (f *FakeTelebot) Sender() *telebot.User {
+	return f.Msg.Sender
+}
+
+func (f *FakeTelebot) Text() string {
+	return f.Msg.Text
+}
+
+func (f *FakeTelebot) Send(what interface{}, opts ...interface{}) error {
+	f.Sent = append(f.Sent, what)
+	return nil
+}
+
+func (f *FakeTelebot) Edit(what interface{}, opts ...interface{}) error {
+	f.Sent = append(f.Sent, what)
+	return nil
+}
+
+func (f *FakeTelebot) Respond(resp ...*telebot.CallbackResponse) error {
+	return nil
+}
+`,
+		},
+	}
+}
+
+// conversationSource lets the users answer the fields one by one instead of typing json
+// and browse the read results, the state is kept per chat
+const conversationSource = `// Field is asked in the chat when a command has no payload,
+// Name is the json name and Type the go type the answer is parsed as
+type Field struct {
+	Name string
+	Type string
+}
+
+// Item is a read result, Delete is nil when the model can't be deleted
+type Item struct {
+	Text   string
+	Delete func() error
+}
+
+type flow struct {
+	fields  []Field
+	values  map[string]interface{}
+	step    int
+	handler telebot.HandlerFunc
+}
+
+type page struct {
+	items []Item
+	index int
+}
+
+var (
+	mu    sync.Mutex
+	flows = make(map[int64]*flow)
+	pages = make(map[int64]*page)
+
+	BtnPrev   = telebot.Btn{Unique: "prev", Text: "« Prev"}
+	BtnNext   = telebot.Btn{Unique: "next", Text: "Next »"}
+	BtnDelete = telebot.Btn{Unique: "delete", Text: "Delete"}
+)
+
+// Ask prompts the fields one by one, the handler is called again
+// with the answers as json payload once every field is answered
+func Ask(ctx telebot.Context, handler telebot.HandlerFunc, fields []Field) error {
+	if len(fields) == 0 {
+		return handler(withPayload(ctx, "{}"))
+	}
+
+	mu.Lock()
+	flows[ctx.Chat().ID] = &flow{
+		fields:  fields,
+		values:  make(map[string]interface{}, len(fields)),
+		handler: handler,
+	}
+	mu.Unlock()
+
+	return prompt(ctx, fields[0])
+}
+
+// Answer handles the text messages, the answer is checked against the type of the asked field
+func Answer(ctx telebot.Context) error {
+	next, done, err := answer(ctx.Chat().ID, ctx.Text())
+	if err != nil {
+		return ctx.Send(err.Error())
+	}
+
+	if next != nil {
+		return prompt(ctx, *next)
+	}
+
+	b, err := json.Marshal(done.values)
+	if err != nil {
+		return ctx.Send(err.Error())
+	}
+
+	return done.handler(withPayload(ctx, string(b)))
+}
+
+// Cancel forgets the questions of the chat
+func Cancel(ctx telebot.Context) error {
+	mu.Lock()
+	delete(flows, ctx.Chat().ID)
+	mu.Unlock()
+
+	return ctx.Send("Cancelled")
+}
+
+// answer records the value of the asked field, it returns the next field or the finished flow
+func answer(chatID int64, text string) (*Field, *flow, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	f, ok := flows[chatID]
+	if !ok {
+		return nil, nil, errors.New("Nothing was asked, send a command first")
+	}
+
+	field := f.fields[f.step]
+	v, err := Parse(field.Type, text)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s must be a %s, try again", field.Name, field.Type)
+	}
+
+	f.values[field.Name] = v
+	f.step++
+	if f.step < len(f.fields) {
+		return &f.fields[f.step], nil, nil
+	}
+
+	delete(flows, chatID)
+	return nil, f, nil
+}
+
+func prompt(ctx telebot.Context, field Field) error {
+	return ctx.Send(fmt.Sprintf("Enter %s (%s), /cancel to stop", field.Name, field.Type))
+}
+
+// Parse converts the answer to the go type of a field, the types other than
+// the basic ones are read as json and kept as text when they are not json
+func Parse(typ, text string) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	switch typ {
+	case "string":
+		return text, nil
+	case "bool":
+		return strconv.ParseBool(text)
+	case "int", "int8", "int16", "int32", "int64":
+		return strconv.ParseInt(text, 10, bitSize(typ, "int"))
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		return strconv.ParseUint(text, 10, bitSize(typ, "uint"))
+	case "float32", "float64":
+		return strconv.ParseFloat(text, bitSize(typ, "float"))
+	}
+
+	var v interface{}
+	err := json.Unmarshal([]byte(text), &v)
+	if err != nil {
+		return text, nil
+	}
+
+	return v, nil
+}
+
+func bitSize(typ, prefix string) int {
+	size, err := strconv.Atoi(strings.TrimPrefix(typ, prefix))
+	if err != nil {
+		return 64
+	}
+
+	return size
+}
+
+// payloadContext is the context of the last answer with the answers as payload
+type payloadContext struct {
+	telebot.Context
+	msg *telebot.Message
+}
+
+func (c *payloadContext) Message() *telebot.Message {
+	return c.msg
+}
+
+func withPayload(ctx telebot.Context, payload string) telebot.Context {
+	msg := *ctx.Message()
+	msg.Payload = payload
+	return &payloadContext{Context: ctx, msg: &msg}
+}
+
+// Paginate sends the first item with the buttons to browse and delete the items
+func Paginate(ctx telebot.Context, items []Item) error {
+	if len(items) == 0 {
+		return ctx.Send("Nothing found")
+	}
+
+	p := &page{items: items}
+	mu.Lock()
+	pages[ctx.Chat().ID] = p
+	text, markup := p.render()
+	mu.Unlock()
+
+	return ctx.Send(text, markup)
+}
+
+// Prev handles BtnPrev
+func Prev(ctx telebot.Context) error {
+	return turn(ctx, -1)
+}
+
+// Next handles BtnNext
+func Next(ctx telebot.Context) error {
+	return turn(ctx, 1)
+}
+
+// Delete handles BtnDelete, the shown item is deleted and the next one is shown
+func Delete(ctx telebot.Context) error {
+	mu.Lock()
+	p, ok := pages[ctx.Chat().ID]
+	if !ok {
+		mu.Unlock()
+		return expired(ctx)
+	}
+	index := p.index
+	item := p.items[index]
+	mu.Unlock()
+
+	if item.Delete == nil {
+		return ctx.Respond(&telebot.CallbackResponse{Text: "Unable to delete"})
+	}
+
+	err := item.Delete()
+	if err != nil {
+		return ctx.Respond(&telebot.CallbackResponse{Text: err.Error()})
+	}
+
+	mu.Lock()
+	if pages[ctx.Chat().ID] != p || index >= len(p.items) {
+		mu.Unlock()
+		return ctx.Respond(&telebot.CallbackResponse{Text: "Deleted"})
+	}
+	p.items = append(p.items[:index], p.items[index+1:]...)
+	if len(p.items) == 0 {
+		delete(pages, ctx.Chat().ID)
+		mu.Unlock()
+		return ctx.Edit("Deleted, nothing left")
+	}
+	if p.index >= len(p.items) {
+		p.index = len(p.items) - 1
+	}
+	text, markup := p.render()
+	mu.Unlock()
+
+	return ctx.Edit(text, markup)
+}
+
+func turn(ctx telebot.Context, step int) error {
+	mu.Lock()
+	p, ok := pages[ctx.Chat().ID]
+	if !ok {
+		mu.Unlock()
+		return expired(ctx)
+	}
+	p.index = (p.index + step + len(p.items)) % len(p.items)
+	text, markup := p.render()
+	mu.Unlock()
+
+	return ctx.Edit(text, markup)
+}
+
+func expired(ctx telebot.Context) error {
+	return ctx.Respond(&telebot.CallbackResponse{Text: "The results are gone, read them again"})
+}
+
+func (p *page) render() (string, *telebot.ReplyMarkup) {
+	item := p.items[p.index]
+	markup := &telebot.ReplyMarkup{}
+	rows := []telebot.Row{markup.Row(BtnPrev, BtnNext)}
+	if item.Delete != nil {
+		rows = append(rows, markup.Row(BtnDelete))
+	}
+	markup.Inline(rows...)
+
+	return fmt.Sprintf("%d/%d\n%s", p.index+1, len(p.items), item.Text), markup
+}
+`
+
+// telebotAllowlistEnv is the variable of the TelebotAllowlist config field
+const telebotAllowlistEnv = consts.ConfigEnvPrefix + "TELEBOT_ALLOWLIST"
+
+// menuSource answers /help and keeps the bot to the allowed users and chats
+const menuSource = `// Help lists the commands of the menu
+func Help(commands []telebot.Command) telebot.HandlerFunc {
+	var sb strings.Builder
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf("/%s - %s\n", c.Text, c.Description))
+	}
+	text := sb.String()
+
+	return func(ctx telebot.Context) error {
+		return ctx.Send(text)
+	}
+}
+
+// Allow lets only the comma separated user or chat ids reach the handlers,
+// everyone is denied when the list is empty. The denied ids are logged, so they can be added
+func Allow(ids string) (telebot.MiddlewareFunc, error) {
+	allowed := make(map[int64]struct{})
+	for _, s := range strings.Split(ids, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse allowlist id %q: %w", s, err)
+		}
+		allowed[id] = struct{}{}
+	}
+	if len(allowed) == 0 {
+		log.Println("the telebot allowlist is empty, every user is denied until their id is added to ` + telebotAllowlistEnv + `")
+	}
+
+	return func(next telebot.HandlerFunc) telebot.HandlerFunc {
+		return func(ctx telebot.Context) error {
+			var userID, chatID int64
+			if user := ctx.Sender(); user != nil {
+				userID = user.ID
+				if _, ok := allowed[user.ID]; ok {
+					return next(ctx)
+				}
+			}
+			if chat := ctx.Chat(); chat != nil {
+				chatID = chat.ID
+				if _, ok := allowed[chat.ID]; ok {
+					return next(ctx)
+				}
+			}
+
+			log.Printf("denied user %d in chat %d, add one of them to ` + telebotAllowlistEnv + ` to let it in", userID, chatID)
+			return ctx.Send("Access denied")
+		}
+	}, nil
+}
+`
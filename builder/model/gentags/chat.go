@@ -0,0 +1,172 @@
+package gentags
+
+import (
+	"fmt"
+	"gowizard/builder/model/chatfw"
+	"gowizard/builder/model/system"
+	"gowizard/consts"
+	"gowizard/util"
+	"path/filepath"
+	"strings"
+)
+
+// Chat generates chat command handlers: the payload is decoded as the json model and
+// the result is replied as json, the platform specific statements come from chatfw.
+// Telegram handlers are conversational: create and update without payload ask the fields
+// one by one and the read results are browsed with inline buttons
+type Chat struct {
+	modelInstance  *system.Model
+	layer          *system.Layer
+	platform       chatfw.Platform
+	conversational bool
+}
+
+const (
+	chatPayload = `payload := strings.TrimSpace(%s)
+if payload == "" {
+	payload = "{}"
+}
+
+`
+
+	chatAsk = `payload := strings.TrimSpace(%s)
+if payload == "" {
+	return chat.Ask(ctx, %s.%s, []chat.Field{
+%s})
+}
+
+`
+
+	chatDecode = `var req %s.%s
+err := json.Unmarshal([]byte(payload), &req)
+if err != nil {
+	%s}
+
+`
+
+	chatCall = `res, err := %s.%s.%s%s(&req)
+if err != nil {
+	%s}
+
+`
+
+	chatReply = `b, err := json.Marshal(res)
+if err != nil {
+	%s}
+
+%s`
+
+	chatPaginate = `items := make([]chat.Item, 0, len(res))
+for i := range res {
+	b, err := json.Marshal(res[i])
+	if err != nil {
+		%s}
+
+	item := chat.Item{Text: string(b)}
+%s	items = append(items, item)
+}
+
+return chat.Paginate(ctx, items)
+`
+
+	chatPaginateDelete = `	item.Delete = func() error {
+		return %s.%s.Delete%s(&res[i])
+	}
+`
+
+	chatDelete = `err = %s.%s.Delete%s(&req)
+if err != nil {
+	%s}
+
+%s`
+)
+
+func NewChat(layer *system.Layer, modelInstance *system.Model) *Chat {
+	return &Chat{
+		layer:          layer,
+		modelInstance:  modelInstance,
+		platform:       chatfw.Select(layer.Type),
+		conversational: layer.Type == consts.TelebotLayerType,
+	}
+}
+
+func (c *Chat) Create() string {
+	return c.body("Create")
+}
+
+func (c *Chat) Read() string {
+	return c.body("Read")
+}
+
+func (c *Chat) Update() string {
+	return c.body("Update")
+}
+
+func (c *Chat) Delete() string {
+	return c.body("Delete")
+}
+
+func (c *Chat) Custom() string {
+	return defaultCustom
+}
+
+// Imports are the imports of the model layer file
+func (c *Chat) Imports(projectName string) []string {
+	imports := append(c.platform.Imports(projectName),
+		util.MakeString("encoding/json"),
+		util.MakeString("strings"),
+	)
+	if c.conversational {
+		imports = append(imports, util.MakeString(filepath.Join(projectName, consts.DefaultChatFolder)))
+	}
+
+	return imports
+}
+
+func (c *Chat) body(method string) string {
+	if c.layer.NextLayer == nil {
+		return defaultError
+	}
+
+	var sb strings.Builder
+	receiver := strings.ToLower(string([]rune(c.modelInstance.Name)[0]))
+	if c.conversational && (method == "Create" || method == "Update") {
+		sb.WriteString(fmt.Sprintf(chatAsk, c.platform.Payload(), receiver, method+c.modelInstance.Name, c.askFields()))
+	} else {
+		sb.WriteString(fmt.Sprintf(chatPayload, c.platform.Payload()))
+	}
+
+	unable := c.platform.Reply(fmt.Sprintf(`"Unable to %s %s: " + err.Error()`,
+		strings.ToLower(method), util.MakePrivateName(c.modelInstance.Name)))
+	sb.WriteString(fmt.Sprintf(chatDecode, consts.DefaultModelsFolder, c.modelInstance.Name, unable))
+
+	failed := c.platform.Reply("err.Error()")
+	switch {
+	case method == "Delete":
+		sb.WriteString(fmt.Sprintf(chatDelete, receiver, c.layer.NextLayer.Name, c.modelInstance.Name,
+			failed, c.platform.Reply(`"Success"`)))
+	case method == "Read" && c.conversational:
+		sb.WriteString(fmt.Sprintf(chatCall, receiver, c.layer.NextLayer.Name, method, c.modelInstance.Name, failed))
+		var deletion string
+		if c.modelInstance.HasMethod(system.MethodDelete) {
+			deletion = fmt.Sprintf(chatPaginateDelete, receiver, c.layer.NextLayer.Name, c.modelInstance.Name)
+		}
+		sb.WriteString(fmt.Sprintf(chatPaginate, failed, deletion))
+	default:
+		sb.WriteString(fmt.Sprintf(chatCall, receiver, c.layer.NextLayer.Name, method, c.modelInstance.Name, failed))
+		sb.WriteString(fmt.Sprintf(chatReply, failed, c.platform.Reply("string(b)")))
+	}
+
+	return sb.String()
+}
+
+// askFields are the chat.Field literals of the model fields, named as in json
+func (c *Chat) askFields() string {
+	var sb strings.Builder
+	for _, f := range c.modelInstance.Fields {
+		name, _, _ := strings.Cut(f.TagValue("json"), ",")
+		sb.WriteString(fmt.Sprintf("{Name: %s, Type: %s},\n", util.MakeString(name), util.MakeString(string(f.Type))))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,148 @@
+package gentags
+
+import (
+	"fmt"
+	"gowizard/builder/model/system"
+	"gowizard/consts"
+	"gowizard/util"
+	"strings"
+)
+
+// CLI generates cobra command handlers, the request model is filled from the command flags
+type CLI struct {
+	modelInstance *system.Model
+	layer         *system.Layer
+}
+
+const (
+	baseCLIBody = `var req %s.%s
+var err error
+%s
+res, err := %s.%s.%s%s(&req)
+if err != nil {
+	return err
+}
+
+return json.NewEncoder(cmd.OutOrStdout()).Encode(res)
+`
+
+	deleteCLIBody = `var req %s.%s
+var err error
+%s
+err = %s.%s.%s%s(&req)
+if err != nil {
+	return err
+}
+
+return json.NewEncoder(cmd.OutOrStdout()).Encode("done")
+`
+)
+
+// cliFlagTypes maps field types to the pflag getters, fields of other types have no flag
+var cliFlagTypes = map[system.FieldType]string{
+	"string":    "String",
+	"bool":      "Bool",
+	"int":       "Int",
+	"int8":      "Int8",
+	"int16":     "Int16",
+	"int32":     "Int32",
+	"int64":     "Int64",
+	"uint":      "Uint",
+	"uint8":     "Uint8",
+	"uint16":    "Uint16",
+	"uint32":    "Uint32",
+	"uint64":    "Uint64",
+	"float32":   "Float32",
+	"float64":   "Float64",
+	"[]string":  "StringSlice",
+	"[]int":     "IntSlice",
+	"[]bool":    "BoolSlice",
+	"[]float64": "Float64Slice",
+}
+
+func NewCLI(layer *system.Layer, modelInstance *system.Model) *CLI {
+	return &CLI{
+		layer:         layer,
+		modelInstance: modelInstance,
+	}
+}
+
+func (c *CLI) Create() string {
+	return c.body(baseCLIBody, "Create")
+}
+
+func (c *CLI) Read() string {
+	return c.body(baseCLIBody, "Read")
+}
+
+func (c *CLI) Update() string {
+	return c.body(baseCLIBody, "Update")
+}
+
+func (c *CLI) Delete() string {
+	return c.body(deleteCLIBody, "Delete")
+}
+
+func (c *CLI) Custom() string {
+	return defaultCustom
+}
+
+// Flags declares a flag per model field on the command variable cmdVar, e.g. --long-description-field
+func (c *CLI) Flags(cmdVar string) string {
+	var sb strings.Builder
+	for _, f := range c.modelInstance.Fields {
+		getter, ok := cliFlagTypes[f.Type]
+		if !ok {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("%s.Flags().%s(%s, %s, %s)\n",
+			cmdVar, getter, util.MakeString(flagName(f)), zeroFlagValue(f.Type), util.MakeString(f.Name)))
+	}
+
+	return sb.String()
+}
+
+func (c *CLI) body(template, method string) string {
+	if c.layer.NextLayer == nil {
+		return defaultError
+	}
+
+	var flags strings.Builder
+	for _, f := range c.modelInstance.Fields {
+		getter, ok := cliFlagTypes[f.Type]
+		if !ok {
+			continue
+		}
+
+		flags.WriteString(fmt.Sprintf("req.%s, err = cmd.Flags().Get%s(%s)\nif err != nil {\n\treturn err\n}\n\n",
+			f.Name, getter, util.MakeString(flagName(f))))
+	}
+
+	return fmt.Sprintf(template,
+		consts.DefaultModelsFolder,
+		c.modelInstance.Name,
+		flags.String(),
+		strings.ToLower(string([]rune(c.modelInstance.Name)[0])),
+		c.layer.NextLayer.Name,
+		method,
+		c.modelInstance.Name,
+	)
+}
+
+func flagName(f system.Field) string {
+	return strings.ReplaceAll(util.PascalToSnakeCase(f.Name), "_", "-")
+}
+
+func zeroFlagValue(ft system.FieldType) string {
+	switch {
+	case ft == "string":
+		return `""`
+	case ft == "bool":
+		return "false"
+	case strings.HasPrefix(string(ft), "[]"):
+		return "nil"
+	default:
+		return "0"
+	}
+}
@@ -0,0 +1,428 @@
+package gentags
+
+import (
+	"fmt"
+	"gowizard/builder/model/system"
+	"gowizard/consts"
+	"strings"
+)
+
+// Consumer generates handlers of queue messages, one topic per model method: a message the next
+// layer fails on is nacked to be redelivered, a payload that can't be decoded is terminated
+type Consumer struct {
+	modelInstance *system.Model
+	layer         *system.Layer
+}
+
+const (
+	baseConsumerBody = `var req %s.%s
+err := json.Unmarshal(msg.Data(), &req)
+if err != nil {
+	// a payload that can't be decoded never will be, it is not redelivered
+	_ = msg.Term()
+	return
+}
+
+_, err = %s.%s.%s%s(&req)
+if err != nil {
+	_ = msg.Nack()
+	return
+}
+
+_ = msg.Ack()
+`
+
+	deleteConsumerBody = `var req %s.%s
+err := json.Unmarshal(msg.Data(), &req)
+if err != nil {
+	// a payload that can't be decoded never will be, it is not redelivered
+	_ = msg.Term()
+	return
+}
+
+err = %s.%s.%s%s(&req)
+if err != nil {
+	_ = msg.Nack()
+	return
+}
+
+_ = msg.Ack()
+`
+)
+
+const (
+	// BrokerSource is the broker package entry point, the broker is chosen by the config
+	BrokerSource = `// Message is a single delivery of a topic, the handler acks, nacks or terminates it once.
+// A nacked message is delivered again, a terminated one never is
+type Message interface {
+	Data() []byte
+	Ack() error
+	Nack() error
+	Term() error
+}
+
+type Handler func(msg Message)
+
+type Broker interface {
+	Subscribe(topic string, handler Handler) error
+	Publish(topic string, data []byte) error
+	// Run delivers messages to the handlers until Close is called
+	Run() error
+	Close() error
+}
+
+// New connects to the broker of the config, nats unless another one is set. The memory broker
+// delivers in process, it is meant for tests
+func New(cfg *config.Config) (Broker, error) {
+	switch cfg.BrokerType {
+	case "", "nats":
+		return NewNats(cfg.BrokerUrls, %[1]s)
+	case "rabbitmq":
+		return NewRabbitMQ(cfg.BrokerUrls, %[1]s)
+	case "memory":
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown broker type %%q", cfg.BrokerType)
+	}
+}
+`
+
+	// MemoryBrokerSource delivers messages in process, so the project can be tested without a live broker
+	MemoryBrokerSource = `// Memory is an in-process broker. Publish delivers the message to the topic
+// handler synchronously, so tests can check the outcome right after publishing
+type Memory struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	done     chan struct{}
+	once     sync.Once
+}
+
+type MemoryMessage struct {
+	data       []byte
+	Acked      bool
+	Nacked     bool
+	Terminated bool
+}
+
+func (m *MemoryMessage) Data() []byte {
+	return m.data
+}
+
+func (m *MemoryMessage) Ack() error {
+	m.Acked = true
+	return nil
+}
+
+func (m *MemoryMessage) Nack() error {
+	m.Nacked = true
+	return nil
+}
+
+func (m *MemoryMessage) Term() error {
+	m.Terminated = true
+	return nil
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		handlers: make(map[string]Handler),
+		done:     make(chan struct{}),
+	}
+}
+
+func (b *Memory) Subscribe(topic string, handler Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[topic] = handler
+	return nil
+}
+
+func (b *Memory) Publish(topic string, data []byte) error {
+	_, err := b.Deliver(topic, data)
+	return err
+}
+
+// Deliver publishes data to the topic and returns the handled message
+func (b *Memory) Deliver(topic string, data []byte) (*MemoryMessage, error) {
+	b.mu.RLock()
+	handler, ok := b.handlers[topic]
+	b.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no subscriber for topic %s", topic)
+	}
+
+	msg := &MemoryMessage{data: data}
+	handler(msg)
+	return msg, nil
+}
+
+func (b *Memory) Run() error {
+	<-b.done
+	return nil
+}
+
+func (b *Memory) Close() error {
+	b.once.Do(func() {
+		close(b.done)
+	})
+	return nil
+}
+`
+
+	// NatsBrokerSource consumes topics of a JetStream stream, nacked messages are redelivered and
+	// terminated ones are dropped
+	NatsBrokerSource = `type Nats struct {
+	conn     *nats.Conn
+	js       nats.JetStreamContext
+	stream   string
+	handlers map[string]Handler
+	done     chan struct{}
+	once     sync.Once
+}
+
+type natsMessage struct {
+	msg *nats.Msg
+}
+
+func (m *natsMessage) Data() []byte {
+	return m.msg.Data
+}
+
+func (m *natsMessage) Ack() error {
+	return m.msg.Ack()
+}
+
+func (m *natsMessage) Nack() error {
+	return m.msg.Nak()
+}
+
+func (m *natsMessage) Term() error {
+	return m.msg.Term()
+}
+
+func NewNats(urls, stream string) (*Nats, error) {
+	conn, err := nats.Connect(urls)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Nats{
+		conn:     conn,
+		js:       js,
+		stream:   stream,
+		handlers: make(map[string]Handler),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+func (b *Nats) Subscribe(topic string, handler Handler) error {
+	b.handlers[topic] = handler
+	return nil
+}
+
+func (b *Nats) Publish(topic string, data []byte) error {
+	_, err := b.js.Publish(topic, data)
+	return err
+}
+
+func (b *Nats) Run() error {
+	topics := make([]string, 0, len(b.handlers))
+	for topic := range b.handlers {
+		topics = append(topics, topic)
+	}
+
+	cfg := &nats.StreamConfig{Name: b.stream, Subjects: topics}
+	_, err := b.js.AddStream(cfg)
+	if errors.Is(err, nats.ErrStreamNameAlreadyInUse) {
+		_, err = b.js.UpdateStream(cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	for topic, handler := range b.handlers {
+		handler := handler
+		_, err = b.js.Subscribe(topic, func(msg *nats.Msg) {
+			handler(&natsMessage{msg: msg})
+		}, nats.ManualAck(), nats.Durable(strings.NewReplacer(".", "_", "*", "_", ">", "_").Replace(topic)))
+		if err != nil {
+			return err
+		}
+	}
+
+	<-b.done
+	return nil
+}
+
+func (b *Nats) Close() error {
+	b.once.Do(func() {
+		close(b.done)
+		b.conn.Close()
+	})
+	return nil
+}
+`
+
+	// RabbitMQBrokerSource consumes a durable queue per topic bound to a topic exchange, nacked messages
+	// are requeued and terminated ones are dropped
+	RabbitMQBrokerSource = `type RabbitMQ struct {
+	conn     *amqp091.Connection
+	ch       *amqp091.Channel
+	exchange string
+	handlers map[string]Handler
+	done     chan struct{}
+	once     sync.Once
+}
+
+type rabbitMQMessage struct {
+	msg amqp091.Delivery
+}
+
+func (m *rabbitMQMessage) Data() []byte {
+	return m.msg.Body
+}
+
+func (m *rabbitMQMessage) Ack() error {
+	return m.msg.Ack(false)
+}
+
+func (m *rabbitMQMessage) Nack() error {
+	return m.msg.Nack(false, true)
+}
+
+func (m *rabbitMQMessage) Term() error {
+	return m.msg.Nack(false, false)
+}
+
+// NewRabbitMQ connects to the first of the comma separated urls that answers
+func NewRabbitMQ(urls, exchange string) (*RabbitMQ, error) {
+	var conn *amqp091.Connection
+	var err error
+	for _, url := range strings.Split(urls, ",") {
+		conn, err = amqp091.Dial(strings.TrimSpace(url))
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	err = ch.ExchangeDeclare(exchange, "topic", true, false, false, false, nil)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return &RabbitMQ{
+		conn:     conn,
+		ch:       ch,
+		exchange: exchange,
+		handlers: make(map[string]Handler),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+func (b *RabbitMQ) Subscribe(topic string, handler Handler) error {
+	b.handlers[topic] = handler
+	return nil
+}
+
+func (b *RabbitMQ) Publish(topic string, data []byte) error {
+	return b.ch.PublishWithContext(context.Background(), b.exchange, topic, false, false, amqp091.Publishing{
+		ContentType: "application/json",
+		Body:        data,
+	})
+}
+
+func (b *RabbitMQ) Run() error {
+	for topic, handler := range b.handlers {
+		queue, err := b.ch.QueueDeclare(topic, true, false, false, false, nil)
+		if err != nil {
+			return err
+		}
+
+		err = b.ch.QueueBind(queue.Name, topic, b.exchange, false, nil)
+		if err != nil {
+			return err
+		}
+
+		deliveries, err := b.ch.Consume(queue.Name, "", false, false, false, false, nil)
+		if err != nil {
+			return err
+		}
+
+		go func(handler Handler, deliveries <-chan amqp091.Delivery) {
+			for msg := range deliveries {
+				handler(&rabbitMQMessage{msg: msg})
+			}
+		}(handler, deliveries)
+	}
+
+	<-b.done
+	return nil
+}
+
+func (b *RabbitMQ) Close() error {
+	b.once.Do(func() {
+		close(b.done)
+		_ = b.conn.Close()
+	})
+	return nil
+}
+`
+)
+
+func NewConsumer(layer *system.Layer, modelInstance *system.Model) *Consumer {
+	return &Consumer{
+		layer:         layer,
+		modelInstance: modelInstance,
+	}
+}
+
+func (c *Consumer) Create() string {
+	return c.body(baseConsumerBody, "Create")
+}
+
+func (c *Consumer) Read() string {
+	return c.body(baseConsumerBody, "Read")
+}
+
+func (c *Consumer) Update() string {
+	return c.body(baseConsumerBody, "Update")
+}
+
+func (c *Consumer) Delete() string {
+	return c.body(deleteConsumerBody, "Delete")
+}
+
+func (c *Consumer) Custom() string {
+	return defaultCustom
+}
+
+func (c *Consumer) body(template, method string) string {
+	if c.layer.NextLayer == nil {
+		return defaultError
+	}
+
+	return fmt.Sprintf(template,
+		consts.DefaultModelsFolder,
+		c.modelInstance.Name,
+		strings.ToLower(string([]rune(c.modelInstance.Name)[0])),
+		c.layer.NextLayer.Name,
+		method,
+		c.modelInstance.Name,
+	)
+}
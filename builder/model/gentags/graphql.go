@@ -0,0 +1,227 @@
+package gentags
+
+import (
+	"fmt"
+	"gowizard/builder/model/system"
+	"gowizard/consts"
+	"gowizard/util"
+	"strings"
+)
+
+// GraphQL generates resolvers of the model queries and mutations and its part of the schema
+type GraphQL struct {
+	modelInstance *system.Model
+	layer         *system.Layer
+}
+
+const (
+	baseGraphQLBody = `res, err := %s.%s.%s%s(%sFromInput(args.Input))
+if err != nil {
+	return nil, err
+}
+
+return &%sResolver{res}, nil
+`
+
+	readGraphQLBody = `res, err := %s.%s.Read%s(%sFromInput(args.Input))
+if err != nil {
+	return nil, err
+}
+
+list := make([]*%sResolver, 0, len(res))
+for i := range res {
+	list = append(list, &%sResolver{&res[i]})
+}
+
+return list, nil
+`
+
+	deleteGraphQLBody = `err := %s.%s.Delete%s(%sFromInput(args.Input))
+if err != nil {
+	return false, err
+}
+
+return true, nil
+`
+)
+
+// graphQLTypes maps go types of the model fields to graphql scalars and the go types
+// graphql-go resolves them with, fields of other types are left out of the schema
+var graphQLTypes = map[system.FieldType][2]string{
+	"string":  {"String", "string"},
+	"bool":    {"Boolean", "bool"},
+	"int":     {"Int", "int32"},
+	"int8":    {"Int", "int32"},
+	"int16":   {"Int", "int32"},
+	"int32":   {"Int", "int32"},
+	"int64":   {"Int", "int32"},
+	"uint":    {"Int", "int32"},
+	"uint8":   {"Int", "int32"},
+	"uint16":  {"Int", "int32"},
+	"uint32":  {"Int", "int32"},
+	"uint64":  {"Int", "int32"},
+	"float32": {"Float", "float64"},
+	"float64": {"Float", "float64"},
+}
+
+func NewGraphQL(layer *system.Layer, modelInstance *system.Model) *GraphQL {
+	return &GraphQL{
+		layer:         layer,
+		modelInstance: modelInstance,
+	}
+}
+
+func (g *GraphQL) Create() string {
+	return g.body("Create")
+}
+
+func (g *GraphQL) Read() string {
+	if g.layer.NextLayer == nil {
+		return defaultError
+	}
+
+	name := g.modelInstance.Name
+	return fmt.Sprintf(readGraphQLBody, g.receiver(), g.layer.NextLayer.Name, name, util.MakePrivateName(name), name, name)
+}
+
+func (g *GraphQL) Update() string {
+	return g.body("Update")
+}
+
+func (g *GraphQL) Delete() string {
+	if g.layer.NextLayer == nil {
+		return defaultError
+	}
+
+	name := g.modelInstance.Name
+	return fmt.Sprintf(deleteGraphQLBody, g.receiver(), g.layer.NextLayer.Name, name, util.MakePrivateName(name))
+}
+
+func (g *GraphQL) Custom() string {
+	return defaultCustom
+}
+
+// Returns are the results of the resolver generated for the method type
+func (g *GraphQL) Returns(mt system.MethodType) []string {
+	switch mt.Lower() {
+	case system.MethodRead:
+		return []string{"[]*" + g.modelInstance.Name + "Resolver", "error"}
+	case system.MethodDelete:
+		return []string{"bool", "error"}
+	default:
+		return []string{"*" + g.modelInstance.Name + "Resolver", "error"}
+	}
+}
+
+// SchemaTypes returns the object and input types of the model
+func (g *GraphQL) SchemaTypes() string {
+	name := g.modelInstance.Name
+
+	var object, input strings.Builder
+	for _, f := range g.modelInstance.Fields {
+		t, ok := graphQLTypes[f.Type]
+		if !ok {
+			object.WriteString(fmt.Sprintf("  # %s %s is not supported by the generator\n", f.Name, f.Type))
+			continue
+		}
+
+		object.WriteString(fmt.Sprintf("  %s: %s!\n", util.MakePrivateName(f.Name), t[0]))
+		input.WriteString(fmt.Sprintf("  %s: %s\n", util.MakePrivateName(f.Name), t[0]))
+	}
+
+	return fmt.Sprintf("type %s {\n%s}\n\ninput %sInput {\n%s}\n", name, object.String(), name, input.String())
+}
+
+// Queries returns the query fields of the model, only Read is a query
+func (g *GraphQL) Queries() []string {
+	queries := make([]string, 0, 1)
+	for _, mt := range g.modelInstance.Methods {
+		if mt.Lower() == system.MethodRead {
+			queries = append(queries, g.schemaField(mt))
+		}
+	}
+
+	return queries
+}
+
+// Mutations returns the mutation fields of the model, every method but Read is a mutation
+func (g *GraphQL) Mutations() []string {
+	mutations := make([]string, 0, len(g.modelInstance.Methods))
+	for _, mt := range g.modelInstance.Methods {
+		if mt.Lower() != system.MethodRead {
+			mutations = append(mutations, g.schemaField(mt))
+		}
+	}
+
+	return mutations
+}
+
+// Types returns the go types used by the resolvers of the model
+func (g *GraphQL) Types() string {
+	name := g.modelInstance.Name
+
+	var input, getters, fromInput strings.Builder
+	for _, f := range g.modelInstance.Fields {
+		t, ok := graphQLTypes[f.Type]
+		if !ok {
+			continue
+		}
+
+		input.WriteString(fmt.Sprintf("%s *%s\n", f.Name, t[1]))
+		getters.WriteString(fmt.Sprintf("func (r *%sResolver) %s() %s {\nreturn %s\n}\n\n",
+			name, f.Name, t[1], convert(t[1], f.Type, "r.m."+f.Name)))
+		fromInput.WriteString(fmt.Sprintf("if in.%s != nil {\nm.%s = %s\n}\n",
+			f.Name, f.Name, convert(string(f.Type), system.FieldType(t[1]), "*in."+f.Name)))
+	}
+
+	return fmt.Sprintf(`type %sArgs struct {
+Input *%sInput
+}
+
+type %sInput struct {
+%s}
+
+type %sResolver struct {
+m *%s.%s
+}
+
+%sfunc %sFromInput(in *%sInput) *%s.%s {
+m := &%s.%s{}
+if in == nil {
+return m
+}
+
+%s
+return m
+}
+`, name, name, name, input.String(), name, consts.DefaultModelsFolder, name,
+		getters.String(), util.MakePrivateName(name), name, consts.DefaultModelsFolder, name,
+		consts.DefaultModelsFolder, name, fromInput.String())
+}
+
+func (g *GraphQL) schemaField(mt system.MethodType) string {
+	name := g.modelInstance.Name
+	field := util.MakePrivateName(mt.GenerateNaming(name))
+	// input is optional everywhere, so every resolver takes the same args
+	switch mt.Lower() {
+	case system.MethodRead:
+		return fmt.Sprintf("%s(input: %sInput): [%s!]!", field, name, name)
+	case system.MethodDelete:
+		return fmt.Sprintf("%s(input: %sInput): Boolean!", field, name)
+	default:
+		return fmt.Sprintf("%s(input: %sInput): %s!", field, name, name)
+	}
+}
+
+func (g *GraphQL) body(method string) string {
+	if g.layer.NextLayer == nil {
+		return defaultError
+	}
+
+	name := g.modelInstance.Name
+	return fmt.Sprintf(baseGraphQLBody, g.receiver(), g.layer.NextLayer.Name, method, name, util.MakePrivateName(name), name)
+}
+
+func (g *GraphQL) receiver() string {
+	return strings.ToLower(string([]rune(g.modelInstance.Name)[0]))
+}
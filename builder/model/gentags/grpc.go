@@ -0,0 +1,237 @@
+package gentags
+
+import (
+	"fmt"
+	"gowizard/builder/model/system"
+	"gowizard/consts"
+	"gowizard/util"
+	"strings"
+)
+
+// GRPC generates the server implementation of the model service and its .proto file
+type GRPC struct {
+	modelInstance *system.Model
+	layer         *system.Layer
+}
+
+const (
+	baseGRPCBody = `res, err := %s.%s.%s%s(%sFromProto(req))
+if err != nil {
+	return nil, err
+}
+
+return %sToProto(res), nil
+`
+
+	readGRPCBody = `res, err := %s.%s.Read%s(%sFromProto(req))
+if err != nil {
+	return nil, err
+}
+
+list := &%s.%sList{Items: make([]*%s.%s, 0, len(res))}
+for i := range res {
+	list.Items = append(list.Items, %sToProto(&res[i]))
+}
+
+return list, nil
+`
+
+	deleteGRPCBody = `err := %s.%s.Delete%s(%sFromProto(req))
+if err != nil {
+	return nil, err
+}
+
+return &%s.%sEmpty{}, nil
+`
+)
+
+// protoTypes maps go types of the model fields to proto scalar types and back to
+// the go types generated by protoc, fields of other types are left out of the message
+var protoTypes = map[system.FieldType][2]string{
+	"string":  {"string", "string"},
+	"bool":    {"bool", "bool"},
+	"int":     {"int64", "int64"},
+	"int8":    {"int32", "int32"},
+	"int16":   {"int32", "int32"},
+	"int32":   {"int32", "int32"},
+	"int64":   {"int64", "int64"},
+	"uint":    {"uint64", "uint64"},
+	"uint8":   {"uint32", "uint32"},
+	"uint16":  {"uint32", "uint32"},
+	"uint32":  {"uint32", "uint32"},
+	"uint64":  {"uint64", "uint64"},
+	"float32": {"float", "float32"},
+	"float64": {"double", "float64"},
+	"[]byte":  {"bytes", "[]byte"},
+}
+
+func NewGRPC(layer *system.Layer, modelInstance *system.Model) *GRPC {
+	return &GRPC{
+		layer:         layer,
+		modelInstance: modelInstance,
+	}
+}
+
+func (g *GRPC) Create() string {
+	return g.body("Create")
+}
+
+func (g *GRPC) Read() string {
+	if g.layer.NextLayer == nil {
+		return defaultError
+	}
+
+	name := g.modelInstance.Name
+	return fmt.Sprintf(readGRPCBody, g.receiver(), g.layer.NextLayer.Name, name, util.MakePrivateName(name),
+		consts.DefaultProtoFolder, name, consts.DefaultProtoFolder, name, util.MakePrivateName(name))
+}
+
+func (g *GRPC) Update() string {
+	return g.body("Update")
+}
+
+func (g *GRPC) Delete() string {
+	if g.layer.NextLayer == nil {
+		return defaultError
+	}
+
+	name := g.modelInstance.Name
+	return fmt.Sprintf(deleteGRPCBody, g.receiver(), g.layer.NextLayer.Name, name, util.MakePrivateName(name),
+		consts.DefaultProtoFolder, name)
+}
+
+func (g *GRPC) Custom() string {
+	return defaultCustom
+}
+
+// Returns are the results of the rpc generated for the method type
+func (g *GRPC) Returns(mt system.MethodType) []string {
+	msg := "*" + consts.DefaultProtoFolder + "." + g.modelInstance.Name
+	switch mt.Lower() {
+	case system.MethodRead:
+		return []string{msg + "List", "error"}
+	case system.MethodDelete:
+		return []string{msg + "Empty", "error"}
+	default:
+		return []string{msg, "error"}
+	}
+}
+
+// Proto returns the .proto file with the model message and its service
+func (g *GRPC) Proto(projectName string) string {
+	name := g.modelInstance.Name
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("syntax = \"proto3\";\n\npackage %s;\n\noption go_package = \"%s/%s\";\n\n",
+		protoPackage(projectName), projectName, consts.DefaultProtoFolder))
+
+	sb.WriteString(fmt.Sprintf("message %s {\n", name))
+	n := 1
+	for _, f := range g.modelInstance.Fields {
+		t, ok := protoTypes[f.Type]
+		if !ok {
+			sb.WriteString(fmt.Sprintf("  // %s %s is not supported by the generator\n", f.Name, f.Type))
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("  %s %s = %d;\n", t[0], util.PascalToSnakeCase(f.Name), n))
+		n++
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString(fmt.Sprintf("message %sList {\n  repeated %s items = 1;\n}\n\n", name, name))
+	sb.WriteString(fmt.Sprintf("message %sEmpty {}\n\n", name))
+
+	sb.WriteString(fmt.Sprintf("service %sService {\n", name))
+	for _, mt := range g.modelInstance.Methods {
+		res := name
+		switch mt.Lower() {
+		case system.MethodRead:
+			res = name + "List"
+		case system.MethodDelete:
+			res = name + "Empty"
+		}
+
+		sb.WriteString(fmt.Sprintf("  rpc %s(%s) returns (%s);\n", mt.GenerateNaming(name), name, res))
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// Converters returns the functions converting the model to the proto message and back
+func (g *GRPC) Converters() string {
+	name := g.modelInstance.Name
+	private := util.MakePrivateName(name)
+
+	var toProto, fromProto strings.Builder
+	for _, f := range g.modelInstance.Fields {
+		t, ok := protoTypes[f.Type]
+		if !ok {
+			continue
+		}
+
+		protoName := protoGoName(util.PascalToSnakeCase(f.Name))
+		toProto.WriteString(fmt.Sprintf("%s: %s,\n", protoName, convert(t[1], f.Type, "m."+f.Name)))
+		fromProto.WriteString(fmt.Sprintf("%s: %s,\n", f.Name, convert(string(f.Type), system.FieldType(t[1]), "p.Get"+protoName+"()")))
+	}
+
+	return fmt.Sprintf(`func %sToProto(m *%s.%s) *%s.%s {
+return &%s.%s{
+%s}
+}
+
+func %sFromProto(p *%s.%s) *%s.%s {
+return &%s.%s{
+%s}
+}
+`, private, consts.DefaultModelsFolder, name, consts.DefaultProtoFolder, name,
+		consts.DefaultProtoFolder, name, toProto.String(),
+		private, consts.DefaultProtoFolder, name, consts.DefaultModelsFolder, name,
+		consts.DefaultModelsFolder, name, fromProto.String())
+}
+
+func (g *GRPC) body(method string) string {
+	if g.layer.NextLayer == nil {
+		return defaultError
+	}
+
+	name := g.modelInstance.Name
+	return fmt.Sprintf(baseGRPCBody, g.receiver(), g.layer.NextLayer.Name, method, name,
+		util.MakePrivateName(name), util.MakePrivateName(name))
+}
+
+func (g *GRPC) receiver() string {
+	return strings.ToLower(string([]rune(g.modelInstance.Name)[0]))
+}
+
+// convert casts the expression from one go type to the other when they differ
+func convert(to string, from system.FieldType, expr string) string {
+	if to == string(from) {
+		return expr
+	}
+
+	return fmt.Sprintf("%s(%s)", to, expr)
+}
+
+// protoPackage makes the proto package name out of the project name
+func protoPackage(projectName string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, projectName)
+}
+
+// protoGoName is the name protoc gives to the go field of a snake case proto field
+func protoGoName(snake string) string {
+	parts := strings.Split(snake, "_")
+	for i := range parts {
+		if parts[i] != "" {
+			parts[i] = util.MakePublicName(parts[i])
+		}
+	}
+
+	return strings.Join(parts, "")
+}
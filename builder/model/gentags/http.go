@@ -2,101 +2,161 @@ package gentags
 
 import (
 	"fmt"
+	"gowizard/builder/model/httpfw"
 	"gowizard/builder/model/system"
 	"gowizard/consts"
+	"gowizard/util"
 	"strings"
 )
 
+// HTTP generates controller handlers, the framework specific statements come from httpfw
 type HTTP struct {
 	modelInstance *system.Model
 	layer         *system.Layer
+	framework     httpfw.Framework
 }
 
 const (
 	baseHTTPBody = `var req %s
-err := ctx.ShouldBindBodyWithJSON(&req)
-if err != nil {
-	ctx.JSON(422, gin.H{"error": err.Error()})
-	return
-}
-
+%s
 res, err := %s.%s.%s%s(&req)
 if err != nil {
-	ctx.JSON(500, gin.H{"error": err.Error()})
-	return
-}
+	%s}
 
-ctx.JSON(200, gin.H{"data": res})
-`
+%s`
 
 	deleteHTTPBody = `var req %s
-err := ctx.ShouldBindBodyWithJSON(&req)
-if err != nil {
-	ctx.JSON(422, gin.H{"error": err.Error()})
-	return
-}
-
-err = %s.%s.%s%s(&req)
+%s
+err %s %s.%s.%s%s(&req)
 if err != nil {
-	ctx.JSON(500, gin.H{"error": err.Error()})
-	return
-}
+	%s}
 
-ctx.JSON(200, gin.H{"data": "done"})
-`
+%s`
 )
 
 func NewHTTP(layer *system.Layer, modelInstance *system.Model) *HTTP {
 	return &HTTP{
 		layer:         layer,
 		modelInstance: modelInstance,
+		framework:     httpfw.Select(layer.Type),
+	}
+}
+
+// Imports returns the packages used by the generated handlers of the model
+func (h *HTTP) Imports() []string {
+	imports := h.framework.Imports()
+	if segment, _ := h.framework.PathID(); segment == "" {
+		return imports
+	}
+
+	for _, mt := range []system.MethodType{system.MethodRead, system.MethodUpdate, system.MethodDelete} {
+		field := h.modelInstance.GetPathIDField(mt)
+		if field == nil || !h.modelInstance.HasMethod(mt) {
+			continue
+		}
+
+		switch {
+		case field.Type == system.FieldTypeID:
+			return append(imports, util.MakeString(consts.MongoBsonURL))
+		case field.Type != system.FieldString:
+			return append(imports, util.MakeString("strconv"))
+		}
 	}
+
+	return imports
 }
 
 func (h *HTTP) Create() string {
-	modelType := fmt.Sprintf("%s.%s", consts.DefaultModelsFolder, h.modelInstance.Name)
-	return fmt.Sprintf(baseHTTPBody,
-		modelType,
-		strings.ToLower(string([]rune(h.modelInstance.Name)[0])),
-		h.layer.NextLayer.Name,
-		"Create",
-		h.modelInstance.Name,
-	)
+	return h.body(baseHTTPBody, system.MethodCreate, "res")
 }
 
 func (h *HTTP) Read() string {
-	modelType := fmt.Sprintf("%s.%s", consts.DefaultModelsFolder, h.modelInstance.Name)
-	return fmt.Sprintf(baseHTTPBody,
-		modelType,
-		strings.ToLower(string([]rune(h.modelInstance.Name)[0])),
-		h.layer.NextLayer.Name,
-		"Read",
-		h.modelInstance.Name,
-	)
+	return h.body(baseHTTPBody, system.MethodRead, "res")
 }
 
 func (h *HTTP) Update() string {
-	modelType := fmt.Sprintf("%s.%s", consts.DefaultModelsFolder, h.modelInstance.Name)
-	return fmt.Sprintf(baseHTTPBody,
-		modelType,
-		strings.ToLower(string([]rune(h.modelInstance.Name)[0])),
-		h.layer.NextLayer.Name,
-		"Update",
-		h.modelInstance.Name,
-	)
+	return h.body(baseHTTPBody, system.MethodUpdate, "res")
 }
 
 func (h *HTTP) Delete() string {
-	modelType := fmt.Sprintf("%s.%s", consts.DefaultModelsFolder, h.modelInstance.Name)
-	return fmt.Sprintf(deleteHTTPBody,
-		modelType,
+	return h.body(deleteHTTPBody, system.MethodDelete, `"done"`)
+}
+
+func (h *HTTP) Custom() string {
+	return defaultCustom
+}
+
+func (h *HTTP) body(template string, method system.MethodType, data string) string {
+	decode, declared := h.decode(method)
+	args := []any{fmt.Sprintf("%s.%s", consts.DefaultModelsFolder, h.modelInstance.Name), decode}
+	if method == system.MethodDelete {
+		assign := ":="
+		if declared {
+			assign = "="
+		}
+		args = append(args, assign)
+	}
+
+	return fmt.Sprintf(template, append(args,
 		strings.ToLower(string([]rune(h.modelInstance.Name)[0])),
 		h.layer.NextLayer.Name,
-		"Delete",
+		method.String(),
 		h.modelInstance.Name,
-	)
+		h.framework.Respond(500, "error", "err.Error()"),
+		h.framework.Reply(200, "data", data),
+	)...)
 }
 
-func (h *HTTP) Custom() string {
-	return defaultCustom
+// decode fills req from the request and reports whether it declares err: the body is decoded, except
+// on read and delete when the framework routes the id of the model in the path
+func (h *HTTP) decode(method system.MethodType) (string, bool) {
+	bind := h.framework.Bind("req") + "if err != nil {\n\t" + h.framework.Respond(422, "error", "err.Error()") + "}\n"
+	segment, value := h.framework.PathID()
+	field := h.modelInstance.GetPathIDField(method)
+	if segment == "" || field == nil {
+		return bind, true
+	}
+
+	set := h.setID(field, value)
+	switch method {
+	case system.MethodRead:
+		// the list of every model is read with no id
+		return fmt.Sprintf("if %s != \"\" {\n%s}\n", value, set), false
+	case system.MethodUpdate:
+		return bind + "\n" + set, true
+	default:
+		return set, field.Type != system.FieldString
+	}
+}
+
+// setID parses the id of the model from value into req
+func (h *HTTP) setID(field *system.Field, value string) string {
+	parse := ""
+	switch {
+	case field.Type == system.FieldString:
+		return fmt.Sprintf("req.%s = %s\n", field.Name, value)
+	case field.Type == system.FieldTypeID:
+		parse = fmt.Sprintf("bson.ObjectIDFromHex(%s)", value)
+	case strings.HasPrefix(string(field.Type), "uint"):
+		parse = fmt.Sprintf("strconv.ParseUint(%s, 10, %s)", value, bitSize(field.Type, "uint"))
+	default:
+		parse = fmt.Sprintf("strconv.ParseInt(%s, 10, %s)", value, bitSize(field.Type, "int"))
+	}
+
+	id := "id"
+	if field.Type != system.FieldTypeID && field.Type != "int64" && field.Type != "uint64" {
+		id = fmt.Sprintf("%s(id)", field.Type)
+	}
+
+	return fmt.Sprintf("id, err := %s\nif err != nil {\n\t%s}\nreq.%s = %s\n",
+		parse, h.framework.Respond(422, "error", "err.Error()"), field.Name, id)
+}
+
+// bitSize is the bit size of the integer type with the prefix, 0 for the size of int
+func bitSize(ft system.FieldType, prefix string) string {
+	if size := strings.TrimPrefix(string(ft), prefix); size != "" {
+		return size
+	}
+
+	return "0"
 }
@@ -0,0 +1,209 @@
+package gentags
+
+import (
+	"fmt"
+	"gowizard/builder/model/system"
+	"gowizard/consts"
+	"gowizard/util"
+	"strings"
+)
+
+// Memory generates a repository keeping the models in a map guarded by a mutex, the keys are
+// auto-incremented ids. Reads and deletes filter by example like db.Where(model).Find does
+type Memory struct {
+	modelInstance *system.Model
+	layer         *system.Layer
+}
+
+// memoryComparable are the field types the example filter compares, other fields are ignored
+var memoryComparable = map[system.FieldType]string{
+	"string":  `""`,
+	"bool":    "false",
+	"int":     "0",
+	"int8":    "0",
+	"int16":   "0",
+	"int32":   "0",
+	"int64":   "0",
+	"uint":    "0",
+	"uint8":   "0",
+	"uint16":  "0",
+	"uint32":  "0",
+	"uint64":  "0",
+	"float32": "0",
+	"float64": "0",
+}
+
+func NewMemory(layer *system.Layer, modelInstance *system.Model) *Memory {
+	return &Memory{
+		layer:         layer,
+		modelInstance: modelInstance,
+	}
+}
+
+func (m *Memory) Create() string {
+	r, model := m.names()
+	return fmt.Sprintf(`%[1]s.mu.Lock()
+defer %[1]s.mu.Unlock()
+
+%[1]s.nextID++
+%[3]s%[1]s.items[%[1]s.nextID] = *%[2]s
+return %[2]s, nil
+`, r, model, m.setID(model, r+".nextID"))
+}
+
+func (m *Memory) Read() string {
+	r, model := m.names()
+	return fmt.Sprintf(`%[1]s.mu.RLock()
+defer %[1]s.mu.RUnlock()
+
+ids := make([]uint, 0, len(%[1]s.items))
+for id, item := range %[1]s.items {
+	if match%[3]s(item, *%[2]s) {
+		ids = append(ids, id)
+	}
+}
+slices.Sort(ids)
+
+%[2]sList := make([]%[4]s.%[3]s, 0, len(ids))
+for _, id := range ids {
+	%[2]sList = append(%[2]sList, %[1]s.items[id])
+}
+return %[2]sList, nil
+`, r, model, m.modelInstance.Name, consts.DefaultModelsFolder)
+}
+
+// Update replaces the model with the same id, a model without one is created like db.Save does
+func (m *Memory) Update() string {
+	r, model := m.names()
+	id := m.idField()
+	if id == nil {
+		return fmt.Sprintf("return %s.Create%s(%s)\n", r, m.modelInstance.Name, model)
+	}
+
+	return fmt.Sprintf(`if %[2]s.%[3]s == 0 {
+	return %[1]s.Create%[4]s(%[2]s)
+}
+
+%[1]s.mu.Lock()
+defer %[1]s.mu.Unlock()
+
+id := uint(%[2]s.%[3]s)
+if _, ok := %[1]s.items[id]; !ok {
+	return nil, fmt.Errorf("%[5]s %%d not found", id)
+}
+
+%[1]s.items[id] = *%[2]s
+return %[2]s, nil
+`, r, model, id.Name, m.modelInstance.Name, util.PascalToSnakeCase(m.modelInstance.Name))
+}
+
+// Delete removes every model matching the example, an empty example is refused like gorm does
+func (m *Memory) Delete() string {
+	r, model := m.names()
+	conditions := make([]string, 0, len(m.modelInstance.Fields))
+	for _, f := range m.modelInstance.Fields {
+		if zero, ok := memoryComparable[f.Type]; ok {
+			conditions = append(conditions, fmt.Sprintf("%s.%s == %s", model, f.Name, zero))
+		}
+	}
+	if len(conditions) == 0 {
+		conditions = append(conditions, "true")
+	}
+
+	return fmt.Sprintf(`if %[4]s {
+	return errors.New("delete of %[5]s requires conditions")
+}
+
+%[1]s.mu.Lock()
+defer %[1]s.mu.Unlock()
+
+for id, item := range %[1]s.items {
+	if match%[3]s(item, *%[2]s) {
+		delete(%[1]s.items, id)
+	}
+}
+return nil
+`, r, model, m.modelInstance.Name, strings.Join(conditions, " && "), util.PascalToSnakeCase(m.modelInstance.Name))
+}
+
+func (m *Memory) Custom() string {
+	return defaultCustom
+}
+
+// Imports are the imports of the model layer file
+func (m *Memory) Imports() []string {
+	imports := []string{util.MakeString("sync")}
+	if m.modelInstance.HasMethod(system.MethodRead) {
+		imports = append(imports, util.MakeString("slices"))
+	}
+	if m.modelInstance.HasMethod(system.MethodDelete) {
+		imports = append(imports, util.MakeString("errors"))
+	}
+	if m.idField() != nil && m.modelInstance.HasMethod(system.MethodUpdate) {
+		imports = append(imports, util.MakeString("fmt"))
+	}
+
+	return imports
+}
+
+// Fields are the storage fields of the repository struct
+func (m *Memory) Fields() []system.Field {
+	return []system.Field{
+		{
+			Name: "mu",
+			Type: "sync.RWMutex",
+		},
+		{
+			Name: "items",
+			Type: system.FieldType("map[uint]" + consts.DefaultModelsFolder + "." + m.modelInstance.Name),
+		},
+		{
+			Name: "nextID",
+			Type: "uint",
+		},
+	}
+}
+
+// Match generates the example filter: every non-zero comparable field of the example must be equal
+func (m *Memory) Match() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("func match%[1]s(item, example %[2]s.%[1]s) bool {\n", m.modelInstance.Name, consts.DefaultModelsFolder))
+	for _, f := range m.modelInstance.Fields {
+		zero, ok := memoryComparable[f.Type]
+		if !ok {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("if example.%[1]s != %[2]s && item.%[1]s != example.%[1]s {\nreturn false\n}\n", f.Name, zero))
+	}
+	sb.WriteString("return true\n}\n")
+
+	return sb.String()
+}
+
+// idField returns the integer ID field which mirrors the map key or nil if there is none
+func (m *Memory) idField() *system.Field {
+	for i, f := range m.modelInstance.Fields {
+		if !strings.EqualFold(f.Name, "id") {
+			continue
+		}
+		if zero, ok := memoryComparable[f.Type]; ok && zero == "0" && !strings.HasPrefix(string(f.Type), "float") {
+			return &m.modelInstance.Fields[i]
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) setID(model, id string) string {
+	field := m.idField()
+	if field == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s.%s = %s(%s)\n", model, field.Name, field.Type, id)
+}
+
+func (m *Memory) names() (string, string) {
+	return strings.ToLower(string([]rune(m.modelInstance.Name)[0])), util.MakePrivateName(m.modelInstance.Name) + "Model"
+}
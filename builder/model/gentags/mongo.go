@@ -0,0 +1,146 @@
+package gentags
+
+import (
+	"fmt"
+	"gowizard/builder/model/system"
+	"gowizard/consts"
+	"gowizard/util"
+	"strings"
+)
+
+type Mongo struct {
+	modelInstance *system.Model
+	layer         *system.Layer
+}
+
+const noIDMongo = "//todo: generated by wizard, model has no id field to update by\npanic(\"implement me\")\n"
+
+func NewMongo(layer *system.Layer, modelInstance *system.Model) *Mongo {
+	return &Mongo{
+		layer:         layer,
+		modelInstance: modelInstance,
+	}
+}
+
+// Imports returns the packages used by the generated method bodies of the model
+func (m *Mongo) Imports() []string {
+	imports := []string{util.MakeString(consts.MongoURL)}
+	hasID := m.modelInstance.GetIDField() != nil
+	// update has no body to generate with no id field
+	update := hasID && m.modelInstance.HasMethod(system.MethodUpdate)
+	if update || m.modelInstance.HasMethod(system.MethodCreate) || m.modelInstance.HasMethod(system.MethodRead) ||
+		m.modelInstance.HasMethod(system.MethodDelete) {
+		imports = append(imports, util.MakeString("context"))
+	}
+
+	if update || m.modelInstance.HasMethod(system.MethodDelete) || (hasID && m.modelInstance.HasMethod(system.MethodCreate)) {
+		imports = append(imports, util.MakeString(consts.MongoBsonURL))
+	}
+	if update || m.modelInstance.HasMethod(system.MethodDelete) {
+		imports = append(imports, util.MakeString("errors"))
+	}
+
+	return imports
+}
+
+func (m *Mongo) Create() string {
+	setID := ""
+	if id := m.modelInstance.GetIDField(); id != nil {
+		setID = fmt.Sprintf("%sModel.%s = res.InsertedID.(bson.ObjectID)\n",
+			util.MakePrivateName(m.modelInstance.Name), id.Name)
+	}
+
+	return fmt.Sprintf(`res, err := %s.db.Collection(%s).InsertOne(context.Background(), %sModel)
+if err != nil {
+	return nil, err
+}
+
+%sreturn %sModel, nil
+`, m.receiver(), m.collection(), util.MakePrivateName(m.modelInstance.Name),
+		setID, util.MakePrivateName(m.modelInstance.Name))
+}
+
+func (m *Mongo) Read() string {
+	return fmt.Sprintf(`var %sModelList []%s.%s
+cur, err := %s.db.Collection(%s).Find(context.Background(), %sModel)
+if err != nil {
+	return nil, err
+}
+
+err = cur.All(context.Background(), &%sModelList)
+return %sModelList, err
+`, util.MakePrivateName(m.modelInstance.Name),
+		consts.DefaultModelsFolder,
+		m.modelInstance.Name,
+		m.receiver(),
+		m.collection(),
+		util.MakePrivateName(m.modelInstance.Name),
+		util.MakePrivateName(m.modelInstance.Name),
+		util.MakePrivateName(m.modelInstance.Name))
+}
+
+// Update replaces the model by its id, a zero id is refused as it matches no document
+func (m *Mongo) Update() string {
+	id := m.modelInstance.GetIDField()
+	if id == nil {
+		return noIDMongo
+	}
+
+	return fmt.Sprintf(`if %[3]sModel.%[4]s.IsZero() {
+	return nil, errors.New("update of %[5]s requires an id")
+}
+
+_, err := %[1]s.db.Collection(%[2]s).ReplaceOne(context.Background(), bson.M{"_id": %[3]sModel.%[4]s}, %[3]sModel)
+if err != nil {
+	return nil, err
+}
+
+return %[3]sModel, nil
+`, m.receiver(), m.collection(), util.MakePrivateName(m.modelInstance.Name), id.Name,
+		util.PascalToSnakeCase(m.modelInstance.Name))
+}
+
+// Delete removes the model by its id, a model with no id field is removed by the example, every
+// field of the document is omitted when empty, so an empty example is refused as it matches anything
+func (m *Mongo) Delete() string {
+	model := util.MakePrivateName(m.modelInstance.Name)
+	if id := m.modelInstance.GetIDField(); id != nil {
+		return fmt.Sprintf(`if %[3]sModel.%[4]s.IsZero() {
+	return errors.New("delete of %[5]s requires an id")
+}
+
+_, err := %[1]s.db.Collection(%[2]s).DeleteOne(context.Background(), bson.M{"_id": %[3]sModel.%[4]s})
+return err
+`, m.receiver(), m.collection(), model, id.Name, util.PascalToSnakeCase(m.modelInstance.Name))
+	}
+
+	return fmt.Sprintf(`filter, err := bson.Marshal(%[3]sModel)
+if err != nil {
+	return err
+}
+
+elements, err := bson.Raw(filter).Elements()
+if err != nil {
+	return err
+}
+if len(elements) == 0 {
+	return errors.New("delete of %[4]s requires conditions")
+}
+
+_, err = %[1]s.db.Collection(%[2]s).DeleteOne(context.Background(), filter)
+return err
+`, m.receiver(), m.collection(), model, util.PascalToSnakeCase(m.modelInstance.Name))
+}
+
+func (m *Mongo) Custom() string {
+	return defaultCustom
+}
+
+func (m *Mongo) receiver() string {
+	return strings.ToLower(string([]rune(m.modelInstance.Name)[0]))
+}
+
+// collection returns the quoted collection name, e.g. "users" for User
+func (m *Mongo) collection() string {
+	return util.MakeString(util.PascalToSnakeCase(m.modelInstance.Name) + "s")
+}
@@ -0,0 +1,16 @@
+package gentags
+
+import (
+	"gowizard/builder/model/system"
+)
+
+// MySQL uses the same GORM bodies as Postgres, only the driver in main.go differs
+type MySQL struct {
+	Postgres
+}
+
+func NewMySQL(layer *system.Layer, modelInstance *system.Model) *MySQL {
+	return &MySQL{
+		Postgres: *NewPostgres(layer, modelInstance),
+	}
+}
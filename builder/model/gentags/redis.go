@@ -0,0 +1,273 @@
+package gentags
+
+import (
+	"fmt"
+	"gowizard/builder/model/system"
+	"gowizard/consts"
+	"gowizard/util"
+	"strings"
+)
+
+// Redis generates a cache decorator of the next layer: reads are cached for the configured ttl
+// and every write bumps the model version, which is a part of the read keys, so cached reads are dropped.
+// The cache is best effort, its failures are logged and the result of the next layer is returned
+type Redis struct {
+	modelInstance *system.Model
+	layer         *system.Layer
+}
+
+const (
+	writeRedisBody = `%[1]sModel, err := %[2]s.%[3]s.%[4]s%[5]s(%[1]sModel)
+if err != nil {
+	return nil, err
+}
+
+%[2]s.invalidate()
+return %[1]sModel, nil
+`
+
+	readRedisBody = `query, err := json.Marshal(%[1]sModel)
+if err != nil {
+	return nil, err
+}
+
+ctx := context.Background()
+version, _ := %[2]s.rdb.Get(ctx, "%[6]s:version").Int64()
+key := fmt.Sprintf("%[6]s:read:%%d:%%s", version, query)
+cached, err := %[2]s.rdb.Get(ctx, key).Bytes()
+if err == nil {
+	var %[1]sModelList []%[7]s.%[5]s
+	if json.Unmarshal(cached, &%[1]sModelList) == nil {
+		return %[1]sModelList, nil
+	}
+}
+
+%[1]sModelList, err := %[2]s.%[3]s.Read%[5]s(%[1]sModel)
+if err != nil {
+	return nil, err
+}
+
+// the cache is best effort, the next layer result is returned anyway
+data, err := json.Marshal(%[1]sModelList)
+if err == nil {
+	%[2]s.rdb.Set(ctx, key, data, %[2]s.config.RedisTtl.Duration)
+}
+
+return %[1]sModelList, nil
+`
+
+	deleteRedisBody = `err := %[2]s.%[3]s.Delete%[5]s(%[1]sModel)
+if err != nil {
+	return err
+}
+
+%[2]s.invalidate()
+return nil
+`
+
+	invalidateRedisFunc = `
+// invalidate bumps the version of the model, a failure leaves the cached reads until the ttl ends
+func (%[1]s *%[2]s) invalidate() {
+	err := %[1]s.rdb.Incr(context.Background(), "%[3]s:version").Err()
+	if err != nil {
+		log.Printf("unable to invalidate the %[3]s cache: %%v", err)
+	}
+}
+`
+)
+
+func NewRedis(layer *system.Layer, modelInstance *system.Model) *Redis {
+	return &Redis{
+		layer:         layer,
+		modelInstance: modelInstance,
+	}
+}
+
+func (r *Redis) Create() string {
+	return r.body(writeRedisBody, "Create")
+}
+
+func (r *Redis) Read() string {
+	return r.body(readRedisBody, "Read")
+}
+
+func (r *Redis) Update() string {
+	return r.body(writeRedisBody, "Update")
+}
+
+func (r *Redis) Delete() string {
+	return r.body(deleteRedisBody, "Delete")
+}
+
+func (r *Redis) Custom() string {
+	return defaultCustom
+}
+
+// Invalidate generates the method dropping the cached reads of the model, the writes call it
+func (r *Redis) Invalidate() string {
+	model := r.modelInstance.GetLayer()
+	return fmt.Sprintf(invalidateRedisFunc,
+		strings.ToLower(string([]rune(r.modelInstance.Name)[0])),
+		model.Name,
+		util.PascalToSnakeCase(r.modelInstance.Name),
+	)
+}
+
+// Imports are the imports of the model layer file
+func (r *Redis) Imports() []string {
+	return []string{
+		util.MakeString("context"),
+		util.MakeString("encoding/json"),
+		util.MakeString("fmt"),
+		util.MakeString("log"),
+		util.MakeString(consts.RedisURL),
+	}
+}
+
+// TestImports are the imports of the model test file
+func (r *Redis) TestImports(projectName string) []string {
+	return []string{
+		util.MakeString("testing"),
+		util.MakeString("time"),
+		util.MakeString(consts.MiniredisURL),
+		util.MakeString(consts.RedisURL),
+		util.MakeString(projectName + "/" + consts.DefaultConfigFolder),
+		util.MakeString(projectName + "/" + consts.DefaultModelsFolder),
+	}
+}
+
+// Tests generates the tests of the decorator against miniredis, the next layer is faked
+// and counts its reads. There is nothing to test if the model can't be read
+func (r *Redis) Tests() string {
+	if r.layer.NextLayer == nil || !r.modelInstance.HasMethod(system.MethodRead) {
+		return ""
+	}
+
+	name := r.modelInstance.Name
+	private := util.MakePrivateName(name)
+	fake := "fake" + name + util.MakePublicName(r.layer.NextLayer.Name)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("type %s struct {\nreads int\n}\n\n", fake))
+	for _, method := range r.modelInstance.Methods {
+		returns := method.GetDefaultReturns(r.modelInstance)
+		sb.WriteString(fmt.Sprintf("func (f *%s) %s(%sModel *%s.%s) ", fake, method.GenerateNaming(name), private, consts.DefaultModelsFolder, name))
+		if len(returns) > 1 {
+			sb.WriteString("(" + strings.Join(returns, ", ") + ")")
+		} else {
+			sb.WriteString(returns[0])
+		}
+
+		switch method.Lower() {
+		case system.MethodRead:
+			sb.WriteString(fmt.Sprintf(" {\nf.reads++\nreturn []%s.%s{*%sModel}, nil\n}\n\n", consts.DefaultModelsFolder, name, private))
+		case system.MethodDelete:
+			sb.WriteString(" {\nreturn nil\n}\n\n")
+		default:
+			sb.WriteString(fmt.Sprintf(" {\nreturn %sModel, nil\n}\n\n", private))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf(`func new%[1]sTestCache(t *testing.T) (%[1]s, *%[2]s, *miniredis.Miniredis) {
+t.Helper()
+
+mr := miniredis.RunT(t)
+rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+t.Cleanup(func() {
+	rdb.Close()
+})
+
+next := &%[2]s{}
+return New%[1]s%[3]s(&%[4]s.%[5]s{RedisTtl: %[4]s.Duration{Duration: time.Minute}}, next, rdb), next, mr
+}
+
+func Test%[1]sReadIsCached(t *testing.T) {
+c, next, _ := new%[1]sTestCache(t)
+
+for i := 0; i < 2; i++ {
+	_, err := c.Read%[1]s(&%[6]s.%[1]s{})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+if next.reads != 1 {
+	t.Fatalf("expected 1 read of the next layer, got %%d", next.reads)
+}
+}
+
+func Test%[1]sReadExpires(t *testing.T) {
+c, next, mr := new%[1]sTestCache(t)
+
+_, err := c.Read%[1]s(&%[6]s.%[1]s{})
+if err != nil {
+	t.Fatal(err)
+}
+
+mr.FastForward(2 * time.Minute)
+_, err = c.Read%[1]s(&%[6]s.%[1]s{})
+if err != nil {
+	t.Fatal(err)
+}
+
+if next.reads != 2 {
+	t.Fatalf("expected 2 reads of the next layer, got %%d", next.reads)
+}
+}
+`, name, fake, util.MakePublicName(r.layer.Name), consts.DefaultConfigFolder, util.MakePublicName(consts.DefaultConfigFolder), consts.DefaultModelsFolder))
+
+	for _, method := range r.modelInstance.Methods {
+		var call string
+		switch method.Lower() {
+		case system.MethodCreate, system.MethodUpdate:
+			call = fmt.Sprintf("_, err = c.%s(&%s.%s{})", method.GenerateNaming(name), consts.DefaultModelsFolder, name)
+		case system.MethodDelete:
+			call = fmt.Sprintf("err = c.%s(&%s.%s{})", method.GenerateNaming(name), consts.DefaultModelsFolder, name)
+		default:
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf(`
+func Test%[1]sInvalidates%[2]s(t *testing.T) {
+c, next, _ := new%[1]sTestCache(t)
+
+_, err := c.Read%[1]s(&%[3]s.%[1]s{})
+if err != nil {
+	t.Fatal(err)
+}
+
+%[4]s
+if err != nil {
+	t.Fatal(err)
+}
+
+_, err = c.Read%[1]s(&%[3]s.%[1]s{})
+if err != nil {
+	t.Fatal(err)
+}
+
+if next.reads != 2 {
+	t.Fatalf("expected 2 reads of the next layer, got %%d", next.reads)
+}
+}
+`, name, method.String(), consts.DefaultModelsFolder, call))
+	}
+
+	return sb.String()
+}
+
+func (r *Redis) body(template, method string) string {
+	if r.layer.NextLayer == nil {
+		return defaultError
+	}
+
+	return fmt.Sprintf(template,
+		util.MakePrivateName(r.modelInstance.Name),
+		strings.ToLower(string([]rune(r.modelInstance.Name)[0])),
+		r.layer.NextLayer.Name,
+		method,
+		r.modelInstance.Name,
+		util.PascalToSnakeCase(r.modelInstance.Name),
+		consts.DefaultModelsFolder,
+	)
+}
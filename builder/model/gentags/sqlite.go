@@ -0,0 +1,16 @@
+package gentags
+
+import (
+	"gowizard/builder/model/system"
+)
+
+// SQLite uses the same GORM bodies as Postgres, only the driver in main.go differs
+type SQLite struct {
+	Postgres
+}
+
+func NewSQLite(layer *system.Layer, modelInstance *system.Model) *SQLite {
+	return &SQLite{
+		Postgres: *NewPostgres(layer, modelInstance),
+	}
+}
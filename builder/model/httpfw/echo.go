@@ -0,0 +1,88 @@
+package httpfw
+
+import (
+	"fmt"
+	"gowizard/consts"
+	"gowizard/util"
+)
+
+type Echo struct{}
+
+func (*Echo) Args() []string {
+	return []string{"ctx", "echo.Context"}
+}
+
+func (*Echo) Returns() []string {
+	return []string{"error"}
+}
+
+func (*Echo) Imports() []string {
+	return []string{util.MakeString(consts.EchoURL)}
+}
+
+func (*Echo) Helpers() string {
+	return ""
+}
+
+func (*Echo) HelpersImports() []string {
+	return nil
+}
+
+func (*Echo) Bind(target string) string {
+	return fmt.Sprintf("err := ctx.Bind(&%s)\n", target)
+}
+
+func (e *Echo) Respond(status int, key, val string) string {
+	return e.Reply(status, key, val)
+}
+
+func (*Echo) Reply(status int, key, val string) string {
+	return fmt.Sprintf("return ctx.JSON(%d, echo.Map{%s: %s})\n", status, util.MakeString(key), val)
+}
+
+func (*Echo) PathID() (string, string) {
+	return "", ""
+}
+
+func (*Echo) Swagger() bool {
+	return true
+}
+
+func (*Echo) RouterImports(projectName string) []string {
+	return []string{
+		"_ " + util.MakeString(projectName+"/docs"),
+		"echoSwagger " + util.MakeString("github.com/swaggo/echo-swagger"),
+		util.MakeString(consts.EchoURL),
+	}
+}
+
+func (*Echo) RouterInit() string {
+	return `e := echo.New()
+
+e.GET("/swagger/*", echoSwagger.WrapHandler)
+`
+}
+
+func (*Echo) Group(name, prefix string) string {
+	return fmt.Sprintf("%s := e.Group(\"%s\")\n", name, prefix)
+}
+
+func (*Echo) Route(group, _, httpMethod, route, handler string) string {
+	if route != "" {
+		route = "/" + route
+	}
+
+	return fmt.Sprintf("%s.%s(\"%s\", %s)\n", group, httpMethod, route, handler)
+}
+
+func (*Echo) Mount(path, handler string) string {
+	return fmt.Sprintf("e.POST(\"%s\", echo.WrapHandler(%s))\n", path, handler)
+}
+
+func (*Echo) MountImports() []string {
+	return nil
+}
+
+func (*Echo) RouterRun(addr string) string {
+	return fmt.Sprintf(runTemplate, fmt.Sprintf("e.Start(%s)", addr))
+}
@@ -0,0 +1,87 @@
+package httpfw
+
+import (
+	"fmt"
+	"gowizard/consts"
+	"gowizard/util"
+	"strings"
+)
+
+type Fiber struct{}
+
+func (*Fiber) Args() []string {
+	return []string{"ctx", "*fiber.Ctx"}
+}
+
+func (*Fiber) Returns() []string {
+	return []string{"error"}
+}
+
+func (*Fiber) Imports() []string {
+	return []string{util.MakeString(consts.FiberURL)}
+}
+
+func (*Fiber) Helpers() string {
+	return ""
+}
+
+func (*Fiber) HelpersImports() []string {
+	return nil
+}
+
+func (*Fiber) Bind(target string) string {
+	return fmt.Sprintf("err := ctx.BodyParser(&%s)\n", target)
+}
+
+func (f *Fiber) Respond(status int, key, val string) string {
+	return f.Reply(status, key, val)
+}
+
+func (*Fiber) Reply(status int, key, val string) string {
+	return fmt.Sprintf("return ctx.Status(%d).JSON(fiber.Map{%s: %s})\n", status, util.MakeString(key), val)
+}
+
+func (*Fiber) PathID() (string, string) {
+	return "", ""
+}
+
+func (*Fiber) Swagger() bool {
+	return true
+}
+
+func (*Fiber) RouterImports(projectName string) []string {
+	return []string{
+		"_ " + util.MakeString(projectName+"/docs"),
+		util.MakeString("github.com/gofiber/swagger"),
+		util.MakeString(consts.FiberURL),
+	}
+}
+
+func (*Fiber) RouterInit() string {
+	return `app := fiber.New()
+
+app.Get("/swagger/*", swagger.HandlerDefault)
+`
+}
+
+func (*Fiber) Group(name, prefix string) string {
+	return fmt.Sprintf("%s := app.Group(\"%s\")\n", name, prefix)
+}
+
+// Route uses the fiber method naming, e.g. Post for POST
+func (*Fiber) Route(group, _, httpMethod, route, handler string) string {
+	method := httpMethod[:1] + strings.ToLower(httpMethod[1:])
+	return fmt.Sprintf("%s.%s(\"/%s\", %s)\n", group, method, route, handler)
+}
+
+func (*Fiber) Mount(path, handler string) string {
+	return fmt.Sprintf("app.Post(\"%s\", adaptor.HTTPHandler(%s))\n", path, handler)
+}
+
+func (*Fiber) MountImports() []string {
+	return []string{util.MakeString(consts.FiberAdaptorURL)}
+}
+
+func (*Fiber) RouterRun(addr string) string {
+	return fmt.Sprintf(runTemplate, fmt.Sprintf("app.Listen(%s)", addr))
+}
@@ -0,0 +1,72 @@
+package httpfw
+
+import (
+	"gowizard/consts"
+)
+
+// Framework describes how the generated controller layer and router talk to a web framework.
+// Imports are returned ready to be written, quoted and aliased when needed.
+type Framework interface {
+	// Args are the name and type pairs of a handler
+	Args() []string
+	// Returns are the results of a handler, nil when there are none
+	Returns() []string
+	// Imports are used by the controller layer files
+	Imports() []string
+	// Helpers is the source of an extra controller layer file, empty if none is needed
+	Helpers() string
+	HelpersImports() []string
+
+	// Bind decodes the request body into target and assigns err
+	Bind(target string) string
+	// Respond writes the status and a {key: val} body and leaves the handler
+	Respond(status int, key, val string) string
+	// Reply is Respond as the last statement of the handler
+	Reply(status int, key, val string) string
+	// PathID is the route segment of the id of a model and the expression reading it in a handler,
+	// empty when the id is read from the body
+	PathID() (segment, value string)
+
+	// Swagger reports whether the router serves swagger docs generated by swag
+	Swagger() bool
+	RouterImports(projectName string) []string
+	// RouterInit creates the engine and mounts swagger when it is supported
+	RouterInit() string
+	// Group creates a router group named name for the prefix, empty if groups are not supported
+	Group(name, prefix string) string
+	// Route registers the handler for the http method on prefix/route
+	Route(group, prefix, httpMethod, route, handler string) string
+	// Mount serves the http.Handler on POST path
+	Mount(path, handler string) string
+	// MountImports are the router imports needed by Mount
+	MountImports() []string
+	// RouterRun starts listening on addr
+	RouterRun(addr string) string
+}
+
+// Select returns the framework of the layer type or nil if the layer is not a http controller
+func Select(layerType string) Framework {
+	switch layerType {
+	case consts.HTTPLayerType:
+		return &Gin{}
+	case consts.NetHTTPLayerType:
+		return &NetHTTP{}
+	case consts.EchoLayerType:
+		return &Echo{}
+	case consts.FiberLayerType:
+		return &Fiber{}
+	default:
+		return nil
+	}
+}
+
+var _ Framework = &Gin{}
+var _ Framework = &NetHTTP{}
+var _ Framework = &Echo{}
+var _ Framework = &Fiber{}
+
+const runTemplate = `err := %s
+if err != nil {
+panic(err.Error())
+}
+`
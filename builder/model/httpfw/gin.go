@@ -0,0 +1,85 @@
+package httpfw
+
+import (
+	"fmt"
+	"gowizard/consts"
+	"gowizard/util"
+)
+
+type Gin struct{}
+
+func (*Gin) Args() []string {
+	return []string{"ctx", "*gin.Context"}
+}
+
+func (*Gin) Returns() []string {
+	return nil
+}
+
+func (*Gin) Imports() []string {
+	return []string{util.MakeString(consts.GinURL)}
+}
+
+func (*Gin) Helpers() string {
+	return ""
+}
+
+func (*Gin) HelpersImports() []string {
+	return nil
+}
+
+func (*Gin) Bind(target string) string {
+	return fmt.Sprintf("err := ctx.ShouldBindBodyWithJSON(&%s)\n", target)
+}
+
+func (g *Gin) Respond(status int, key, val string) string {
+	return g.Reply(status, key, val) + "return\n"
+}
+
+func (*Gin) Reply(status int, key, val string) string {
+	return fmt.Sprintf("ctx.JSON(%d, gin.H{%s: %s})\n", status, util.MakeString(key), val)
+}
+
+func (*Gin) PathID() (string, string) {
+	return "", ""
+}
+
+func (*Gin) Swagger() bool {
+	return true
+}
+
+func (*Gin) RouterImports(projectName string) []string {
+	return []string{
+		"_ " + util.MakeString(projectName+"/docs"),
+		"swaggerfiles " + util.MakeString("github.com/swaggo/files"),
+		"ginSwagger " + util.MakeString("github.com/swaggo/gin-swagger"),
+		util.MakeString(consts.GinURL),
+	}
+}
+
+func (*Gin) RouterInit() string {
+	return `g := gin.New()
+
+g.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+`
+}
+
+func (*Gin) Group(name, prefix string) string {
+	return fmt.Sprintf("%s := g.Group(\"%s\")\n", name, prefix)
+}
+
+func (*Gin) Route(group, _, httpMethod, route, handler string) string {
+	return fmt.Sprintf("%s.%s(\"/%s\", %s)\n", group, httpMethod, route, handler)
+}
+
+func (*Gin) Mount(path, handler string) string {
+	return fmt.Sprintf("g.POST(\"%s\", gin.WrapH(%s))\n", path, handler)
+}
+
+func (*Gin) MountImports() []string {
+	return nil
+}
+
+func (*Gin) RouterRun(addr string) string {
+	return fmt.Sprintf(runTemplate, fmt.Sprintf("g.Run(%s)", addr))
+}
@@ -0,0 +1,95 @@
+package httpfw
+
+import (
+	"fmt"
+	"gowizard/util"
+)
+
+// NetHTTP is the standard library ServeMux with method and pattern routes
+type NetHTTP struct{}
+
+const netHTTPHelpers = `func decodeJSON(r *http.Request, v any) error {
+	return json.NewDecoder(r.Body).Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+`
+
+func (*NetHTTP) Args() []string {
+	return []string{"w", "http.ResponseWriter", "r", "*http.Request"}
+}
+
+func (*NetHTTP) Returns() []string {
+	return nil
+}
+
+func (*NetHTTP) Imports() []string {
+	return []string{util.MakeString("net/http")}
+}
+
+func (*NetHTTP) Helpers() string {
+	return netHTTPHelpers
+}
+
+func (*NetHTTP) HelpersImports() []string {
+	return []string{
+		util.MakeString("encoding/json"),
+		util.MakeString("net/http"),
+	}
+}
+
+func (*NetHTTP) Bind(target string) string {
+	return fmt.Sprintf("err := decodeJSON(r, &%s)\n", target)
+}
+
+func (n *NetHTTP) Respond(status int, key, val string) string {
+	return n.Reply(status, key, val) + "return\n"
+}
+
+func (*NetHTTP) Reply(status int, key, val string) string {
+	return fmt.Sprintf("writeJSON(w, %d, map[string]any{%s: %s})\n", status, util.MakeString(key), val)
+}
+
+func (*NetHTTP) PathID() (string, string) {
+	return "{id}", `r.PathValue("id")`
+}
+
+func (*NetHTTP) Swagger() bool {
+	return false
+}
+
+func (*NetHTTP) RouterImports(_ string) []string {
+	return []string{util.MakeString("net/http")}
+}
+
+func (*NetHTTP) RouterInit() string {
+	return "mux := http.NewServeMux()\n"
+}
+
+func (*NetHTTP) Group(_, _ string) string {
+	return ""
+}
+
+func (*NetHTTP) Route(_, prefix, httpMethod, route, handler string) string {
+	if route != "" {
+		prefix += "/" + route
+	}
+
+	return fmt.Sprintf("mux.HandleFunc(\"%s %s\", %s)\n", httpMethod, prefix, handler)
+}
+
+func (*NetHTTP) Mount(path, handler string) string {
+	return fmt.Sprintf("mux.Handle(\"POST %s\", %s)\n", path, handler)
+}
+
+func (*NetHTTP) MountImports() []string {
+	return nil
+}
+
+func (*NetHTTP) RouterRun(addr string) string {
+	return fmt.Sprintf(runTemplate, fmt.Sprintf("http.ListenAndServe(%s, mux)", addr))
+}
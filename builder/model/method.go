@@ -9,6 +9,8 @@ import (
 
 type InterfaceMethodInstance struct {
 	Name string
+	// Embedded writes Name as an embedded interface, Args and Returns are ignored
+	Embedded bool
 	// Args should be a pairs of type and name
 	Args    []string
 	Returns []string `json:"returns"`
@@ -76,12 +78,30 @@ func SelectMethods(mdl *system.Model, layer *system.Layer) GenerateMethodBody {
 	}
 
 	switch layer.Type {
-	case consts.HTTPLayerType:
+	case consts.HTTPLayerType, consts.NetHTTPLayerType, consts.EchoLayerType, consts.FiberLayerType:
 		return gentags.NewHTTP(layer, mdl)
 	case consts.RepoLayerType:
 		return gentags.NewPostgres(layer, mdl)
-	case consts.TelebotLayerType:
-		return gentags.NewTelebot(layer, mdl)
+	case consts.MySQLLayerType:
+		return gentags.NewMySQL(layer, mdl)
+	case consts.SQLiteLayerType:
+		return gentags.NewSQLite(layer, mdl)
+	case consts.MongoLayerType:
+		return gentags.NewMongo(layer, mdl)
+	case consts.MemoryLayerType:
+		return gentags.NewMemory(layer, mdl)
+	case consts.RedisLayerType:
+		return gentags.NewRedis(layer, mdl)
+	case consts.TelebotLayerType, consts.DiscordLayerType, consts.SlackLayerType:
+		return gentags.NewChat(layer, mdl)
+	case consts.ConsumerLayerType:
+		return gentags.NewConsumer(layer, mdl)
+	case consts.CLILayerType:
+		return gentags.NewCLI(layer, mdl)
+	case consts.GRPCLayerType:
+		return gentags.NewGRPC(layer, mdl)
+	case consts.GraphQLLayerType:
+		return gentags.NewGraphQL(layer, mdl)
 	default:
 		return gentags.NewCustom(layer, mdl)
 	}
@@ -97,5 +117,14 @@ type GenerateMethodBody interface {
 
 var _ GenerateMethodBody = &gentags.Custom{}
 var _ GenerateMethodBody = &gentags.HTTP{}
-var _ GenerateMethodBody = &gentags.Telebot{}
+var _ GenerateMethodBody = &gentags.Chat{}
+var _ GenerateMethodBody = &gentags.Consumer{}
+var _ GenerateMethodBody = &gentags.CLI{}
+var _ GenerateMethodBody = &gentags.GRPC{}
+var _ GenerateMethodBody = &gentags.GraphQL{}
 var _ GenerateMethodBody = &gentags.Postgres{}
+var _ GenerateMethodBody = &gentags.MySQL{}
+var _ GenerateMethodBody = &gentags.SQLite{}
+var _ GenerateMethodBody = &gentags.Mongo{}
+var _ GenerateMethodBody = &gentags.Memory{}
+var _ GenerateMethodBody = &gentags.Redis{}
@@ -1,9 +1,12 @@
 package system
 
 import (
+	"gowizard/builder/model/chatfw"
+	"gowizard/builder/model/httpfw"
 	"gowizard/consts"
 	"gowizard/util"
 	"net/http"
+	"slices"
 	"strings"
 )
 
@@ -93,11 +96,22 @@ func (mt MethodType) GetDefaultReturns(mdl *Model) []string {
 }
 
 func (mt MethodType) GetDefaultArgs(mdl *Model, layer *Layer) []string {
+	if fw := httpfw.Select(layer.Type); fw != nil {
+		return fw.Args()
+	}
+	if platform := chatfw.Select(layer.Type); platform != nil {
+		return platform.Args()
+	}
+
 	switch layer.Type {
-	case consts.HTTPLayerType:
-		return []string{"ctx", "*gin.Context"}
-	case consts.TelebotLayerType:
-		return []string{"m", "*telebot.Message"}
+	case consts.ConsumerLayerType:
+		return []string{"msg", consts.DefaultBrokerFolder + ".Message"}
+	case consts.CLILayerType:
+		return []string{"cmd", "*cobra.Command", "args", "[]string"}
+	case consts.GRPCLayerType:
+		return []string{"ctx", "context.Context", "req", "*" + consts.DefaultProtoFolder + "." + mdl.Name}
+	case consts.GraphQLLayerType:
+		return []string{"args", mdl.Name + "Args"}
 	}
 
 	return []string{util.MakePrivateName(mdl.Name + "Model"), " *" + consts.DefaultModelsFolder + "." + mdl.Name}
@@ -113,6 +127,63 @@ var DefaultMethodNamings = map[MethodType]string{
 type Field struct {
 	Name string    `yaml:"name"`
 	Type FieldType `yaml:"type"`
+	Tags []Tag     `yaml:"tags"`
+}
+
+// StructTag builds the struct tag with the given keys, the snake case name is used
+// unless the field overrides the key, extra field tags are appended at the end
+func (f Field) StructTag(keys []string) string {
+	tags := make([]string, 0, len(keys)+len(f.Tags))
+	for _, key := range keys {
+		tags = append(tags, key+":"+util.MakeString(f.TagValue(key)))
+	}
+
+	for _, t := range f.Tags {
+		if !slices.Contains(keys, t.Key) {
+			tags = append(tags, t.Key+":"+util.MakeString(t.Val))
+		}
+	}
+
+	return strings.Join(tags, " ")
+}
+
+// TagValue is the value of the struct tag key, the snake case name unless the field overrides it
+func (f Field) TagValue(key string) string {
+	val := util.PascalToSnakeCase(f.Name)
+	for _, t := range f.Tags {
+		if t.Key == key {
+			val = t.Val
+		}
+	}
+
+	return val
+}
+
+// ConfigField is a field of the generated config, the spec declares extra ones next to the fields of the layers.
+// Type is string (default), int, bool, duration or []string, Default is text and lists are comma separated
+type ConfigField struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	Default     string `yaml:"default"`
+	Required    bool   `yaml:"required"`
+	Description string `yaml:"description"`
+}
+
+const (
+	ConfigString   = "string"
+	ConfigInt      = "int"
+	ConfigBool     = "bool"
+	ConfigDuration = "duration"
+	ConfigList     = "[]string"
+)
+
+// GoType is the type of the field in the config struct, durations are the generated config.Duration
+func (cf ConfigField) GoType() FieldType {
+	if cf.Type == ConfigDuration {
+		return "Duration"
+	}
+
+	return FieldType(cf.Type)
 }
 
 type Tag struct {
@@ -131,10 +202,85 @@ const (
 	FieldObject  FieldType = "object" // better not to use it outside the mongodb
 )
 
+// pathIDTypes are the types of an ID field parsed from a route path
+var pathIDTypes = []FieldType{FieldString, FieldTypeInt, "int8", "int16", "int32", "int64",
+	"uint", "uint8", "uint16", "uint32", "uint64"}
+
+const (
+	MongoObjectID = "bson.ObjectID"
+	MongoObject   = "map[string]interface{}"
+)
+
 func (m *Model) GetFilename() string {
 	return util.PascalToSnakeCase(m.Name) + ".go"
 }
 
+// GetIDField returns the first field with the id type or nil if there is none
+func (m *Model) GetIDField() *Field {
+	for i := range m.Fields {
+		if m.Fields[i].Type == FieldTypeID {
+			return &m.Fields[i]
+		}
+	}
+
+	return nil
+}
+
+// GetPathIDField returns the field a read, update or delete route takes from its path, the id field or
+// an integer or string field named ID, nil if the method or the model has none
+func (m *Model) GetPathIDField(mt MethodType) *Field {
+	switch mt.Lower() {
+	case MethodRead, MethodUpdate, MethodDelete:
+	default:
+		return nil
+	}
+
+	if id := m.GetIDField(); id != nil {
+		return id
+	}
+
+	for i := range m.Fields {
+		if strings.EqualFold(m.Fields[i].Name, "ID") && slices.Contains(pathIDTypes, m.Fields[i].Type) {
+			return &m.Fields[i]
+		}
+	}
+
+	return nil
+}
+
+func (m *Model) HasMethod(mt MethodType) bool {
+	for _, method := range m.Methods {
+		if method.Lower() == mt.Lower() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ForMongo returns a copy of the model as it is stored in mongodb: id fields become
+// the document ObjectID, objects become maps and every field is omitted when empty,
+// so the model can be used as a query by example
+func (m *Model) ForMongo() *Model {
+	mdl := *m
+	mdl.Fields = make([]Field, len(m.Fields))
+	for i, f := range m.Fields {
+		bsonName := util.PascalToSnakeCase(f.Name)
+		switch f.Type {
+		case FieldTypeID:
+			f.Type = MongoObjectID
+			bsonName = "_id"
+		case FieldObject:
+			f.Type = MongoObject
+		}
+
+		f.Tags = append([]Tag{{Key: "bson", Val: bsonName + ",omitempty"}}, f.Tags...)
+		mdl.Fields[i] = f
+	}
+
+	return &mdl
+}
+
 func (m *Model) GetPointerName() string {
 	return strings.ToLower(string([]rune(m.Name)[0])) + " *" + m.Name
 }
@@ -146,9 +292,46 @@ func (m *Model) GetLayer() Model {
 }
 
 type Layer struct {
-	Name      string
-	Type      string
+	Name string
+	Type string
+	// Poller is the telebot update source, long (default) or webhook
+	Poller    string
 	Path      string
 	Models    *[]*Model
 	NextLayer *Layer
 }
+
+// IsHTTP reports whether the layer is a controller of one of the httpfw frameworks
+func (l *Layer) IsHTTP() bool {
+	return httpfw.Select(l.Type) != nil
+}
+
+// IsChat reports whether the layer is a controller of one of the chatfw platforms
+func (l *Layer) IsChat() bool {
+	return chatfw.Select(l.Type) != nil
+}
+
+// IsTransport reports whether the layer is an entry point, transport layers in a row
+// are siblings calling the same next layer
+func (l *Layer) IsTransport() bool {
+	if l.IsHTTP() || l.IsChat() {
+		return true
+	}
+
+	switch l.Type {
+	case consts.GRPCLayerType, consts.GraphQLLayerType, consts.ConsumerLayerType, consts.CLILayerType:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsGorm reports whether the layer is a repository backed by GORM
+func (l *Layer) IsGorm() bool {
+	switch l.Type {
+	case consts.RepoLayerType, consts.MySQLLayerType, consts.SQLiteLayerType:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,163 @@
+package builder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gowizard/builder/fsys"
+	"gowizard/consts"
+	"gowizard/util"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Patch writes onto the disk only the files changed from the previous spec to this one: the new
+// files are created and the files on the disk get the change merged in, so the edits made by hand
+// stay. Both specs are generated in memory first, a file the change can't be merged into is
+// written next to it with the consts.LockNewSuffix
+func (b *Builder) Patch(previous *Builder) error {
+	err := b.checkTools()
+	if err != nil {
+		return err
+	}
+
+	previous.DryRun = true
+	err = previous.CodeGenerate()
+	if err != nil {
+		return fmt.Errorf("unable to generate previous spec: %w", err)
+	}
+
+	b.DryRun = true
+	err = b.CodeGenerate()
+	if err != nil {
+		return err
+	}
+
+	if previous.Path != b.Path {
+		return fmt.Errorf("the project moved from %s to %s, run generate", previous.Path, b.Path)
+	}
+
+	before, ok := previous.FS.(*fsys.Memory)
+	if !ok {
+		return errors.New("the previous spec needs a dry run")
+	}
+	after, ok := b.FS.(*fsys.Memory)
+	if !ok {
+		return errors.New("the spec needs a dry run")
+	}
+
+	generatedBefore := make(map[string]bool)
+	for _, path := range before.Paths() {
+		generatedBefore[path] = true
+	}
+
+	disk := fsys.NewOS()
+	lockPath := filepath.Join(b.Path, consts.LockFile)
+	lock, err := readLock(disk, lockPath)
+	if err != nil {
+		return err
+	}
+
+	// conflicts leaves the spec out of the lock, so the next generation doesn't skip them
+	var conflicts bool
+	for _, path := range after.Paths() {
+		theirs, err := after.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to read generated %s: %w", path, err)
+		}
+		theirs = formatFile(path, theirs)
+
+		var base []byte
+		if generatedBefore[path] {
+			base, err = before.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("unable to read generated %s: %w", path, err)
+			}
+			base = formatFile(path, base)
+
+			// the change doesn't touch the file
+			if bytes.Equal(base, theirs) {
+				continue
+			}
+		}
+
+		rel, err := filepath.Rel(b.Path, path)
+		if err != nil {
+			return fmt.Errorf("unable to locate %s in the project: %w", path, err)
+		}
+
+		merged := theirs
+		current, err := disk.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			err = nil
+			b.Report = append(b.Report, "created "+rel)
+		case err != nil:
+			return fmt.Errorf("unable to read %s: %w", path, err)
+		case bytes.Equal(current, theirs):
+			continue
+		case base == nil:
+			err = errors.New("the file was not generated before")
+		default:
+			merged, err = util.Merge3(base, current, theirs)
+			if err == nil {
+				merged = formatFile(path, merged)
+				b.Report = append(b.Report, "patched "+rel)
+			}
+		}
+
+		if err != nil {
+			conflicts = true
+			b.Report = append(b.Report, fmt.Sprintf("written %s%s, the change conflicts with %s: %s", rel, consts.LockNewSuffix, rel, err.Error()))
+			err = writeFile(disk, path+consts.LockNewSuffix, theirs)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = disk.MkdirAll(filepath.Dir(path))
+		if err != nil {
+			return fmt.Errorf("unable to create directory of %s: %w", path, err)
+		}
+		err = writeFile(disk, path, merged)
+		if err != nil {
+			return err
+		}
+		lock.Files[filepath.ToSlash(rel)] = fileHash(path, theirs)
+	}
+
+	// a project generated with no lock is left with none, the lock of a part of the files would
+	// turn the others into conflicts
+	if _, err := os.Stat(lockPath); err == nil {
+		lock.Spec = ""
+		if !conflicts {
+			lock.Spec, err = b.specHash()
+			if err != nil {
+				return err
+			}
+		}
+
+		err = lock.write(disk, lockPath)
+		if err != nil {
+			return fmt.Errorf("unable to write lock: %w", err)
+		}
+	}
+
+	if b.LayerController.HaveGRPC {
+		err = b.protocGenerate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if b.LayerController.HaveSwagger {
+		err = b.swaggerGenerate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"gowizard/builder/fsys"
+	"gowizard/consts"
+	"gowizard/util"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Plan states of the generated files
+const (
+	planNew       = "new"
+	planChanged   = "changed"
+	planConflict  = "conflict"
+	planUnchanged = "unchanged"
+)
+
+// Plan compares the files of a dry run with the disk, each path is marked new, changed, unchanged or
+// conflict when it is edited since the last generation, a unified diff follows for the changed and
+// the conflicting ones
+func (b *Builder) Plan() (string, error) {
+	mem, ok := b.FS.(*fsys.Memory)
+	if !ok {
+		return "", errors.New("the plan needs a dry run")
+	}
+
+	lock, err := readLock(fsys.NewOS(), filepath.Join(b.Path, consts.LockFile))
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		plan, diffs strings.Builder
+		counts      = make(map[string]int, 4)
+	)
+	for _, path := range mem.Paths() {
+		generated, err := mem.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("unable to read generated %s: %w", path, err)
+		}
+
+		// a real run formats the go files with go fmt
+		generated = formatFile(path, generated)
+
+		rel, err := filepath.Rel(b.Path, path)
+		if err != nil {
+			return "", fmt.Errorf("unable to locate %s in the project: %w", path, err)
+		}
+
+		state := planChanged
+		current, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			state = planNew
+		case err != nil:
+			return "", fmt.Errorf("unable to read %s: %w", path, err)
+		default:
+			switch lock.action(rel, generated, current) {
+			case lockKeep:
+				state = planUnchanged
+			case lockConflict:
+				state = planConflict
+			}
+			if state != planUnchanged {
+				diffs.WriteString(util.UnifiedDiff(filepath.ToSlash(rel), current, generated))
+			}
+		}
+
+		counts[state]++
+		plan.WriteString(fmt.Sprintf("%-9s %s\n", state, path))
+	}
+
+	plan.WriteString(fmt.Sprintf("\n%d %s, %d %s, %d %s, %d %s\n",
+		counts[planNew], planNew, counts[planChanged], planChanged, counts[planConflict], planConflict, counts[planUnchanged], planUnchanged))
+	if diffs.Len() > 0 {
+		plan.WriteString("\n" + diffs.String())
+	}
+
+	return plan.String(), nil
+}
@@ -0,0 +1,555 @@
+package builder
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"gopkg.in/yaml.v3"
+	"gowizard/builder/gen"
+	"gowizard/builder/model/system"
+	"gowizard/consts"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Diagnostic is a problem of the spec at a line and a column of the file
+type Diagnostic struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// specKeys are the keys known at each level of the spec
+var (
+	specKeys        = []string{"project_name", "unsafe", "path", "config_format", "config", "environments", "layers", "models"}
+	layerKeys       = []string{"layer", "tag", "poller"}
+	modelKeys       = []string{"name", "fields", "methods"}
+	fieldKeys       = []string{"name", "type", "tags"}
+	tagKeys         = []string{"key", "val"}
+	configFieldKeys = []string{"name", "type", "default", "required", "description"}
+)
+
+// layerTags are the tags known by the layer controller, a layer with no tag is a plain one like a service
+var layerTags = []string{
+	consts.HTTPLayerType, consts.NetHTTPLayerType, consts.EchoLayerType, consts.FiberLayerType,
+	consts.GRPCLayerType, consts.GraphQLLayerType, consts.ConsumerLayerType, consts.CLILayerType,
+	consts.TelebotLayerType, consts.DiscordLayerType, consts.SlackLayerType,
+	consts.RepoLayerType, consts.MySQLLayerType, consts.SQLiteLayerType, consts.MongoLayerType,
+	consts.MemoryLayerType, consts.RedisLayerType,
+}
+
+// goTypes are the predeclared types usable by the model fields
+var goTypes = []string{
+	"any", "bool", "byte", "complex64", "complex128", "error", "float32", "float64",
+	"int", "int8", "int16", "int32", "int64", "rune", "string",
+	"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+}
+
+// mongoTypes are the field types generated only for the mongodb layers
+var mongoTypes = []string{string(system.FieldTypeID), string(system.FieldObject)}
+
+// generatedFolders are the packages written next to the layers
+var generatedFolders = []string{
+	consts.DefaultModelsFolder, consts.DefaultConfigFolder, consts.DefaultRouterFolder,
+	consts.DefaultTelerouterFolder, consts.DefaultDiscordRouterFolder, consts.DefaultSlackRouterFolder,
+	consts.DefaultChatFolder, consts.DefaultProtoFolder, consts.DefaultGRPCServerFolder,
+	consts.DefaultBrokerFolder, consts.DefaultSubscriberFolder, consts.DefaultCommandsFolder,
+}
+
+var configTypes = []string{system.ConfigString, system.ConfigInt, system.ConfigBool, system.ConfigDuration, system.ConfigList}
+
+// Validate walks the spec and reports every problem found, sorted by position,
+// the error is returned only when the spec is not yaml at all
+func Validate(spec []byte) ([]Diagnostic, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(spec, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	if len(doc.Content) == 0 {
+		return []Diagnostic{{Line: 1, Column: 1, Message: "the spec is empty"}}, nil
+	}
+
+	v := &validator{}
+	v.spec(doc.Content[0])
+
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		a, b := v.diagnostics[i], v.diagnostics[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return v.diagnostics, nil
+}
+
+type validator struct {
+	diagnostics []Diagnostic
+	// chain and configFields are the declared layers and config fields, the models and the environments use them
+	chain        []*system.Layer
+	configFields []string
+}
+
+func (v *validator) report(n *yaml.Node, format string, args ...any) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		Line:    n.Line,
+		Column:  n.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) spec(n *yaml.Node) {
+	values := v.mapping(n, "the spec", specKeys)
+	if values == nil {
+		return
+	}
+
+	if name, ok := v.scalar(values["project_name"], "project_name"); ok && name == "" {
+		v.report(values["project_name"], "project_name is empty")
+	}
+	v.scalar(values["path"], "path")
+
+	if n := values["unsafe"]; n != nil {
+		if _, ok := v.scalar(n, "unsafe"); ok && n.Tag != "!!bool" {
+			v.report(n, "unsafe must be true or false, got '%s'", n.Value)
+		}
+	}
+
+	if format, ok := v.scalar(values["config_format"], "config_format"); ok {
+		switch format {
+		case consts.ConfigFormatJSON, consts.ConfigFormatYAML, consts.ConfigFormatTOML:
+		default:
+			v.report(values["config_format"], "unknown config format '%s', use json, yaml or toml", format)
+		}
+	}
+
+	if n := values["config"]; n != nil {
+		v.config(n)
+	}
+	if n := values["layers"]; n != nil {
+		v.layers(n)
+	}
+	if n := values["environments"]; n != nil {
+		v.environments(n)
+	}
+	if values["models"] == nil {
+		v.report(n, "the spec has no models, add models: with a model to generate")
+	} else {
+		v.models(values["models"])
+	}
+}
+
+// layerEntry is a layer of the chain with the nodes to report on
+type layerEntry struct {
+	name, tag, poller string
+	node              *yaml.Node
+}
+
+func (v *validator) layers(n *yaml.Node) {
+	var (
+		chain    []layerEntry
+		declared = make(map[string]*yaml.Node)
+	)
+	for _, item := range v.sequence(n, "layers") {
+		values := v.mapping(item, "layer", layerKeys)
+		if values == nil {
+			continue
+		}
+
+		entry := layerEntry{node: item}
+		name, ok := v.scalar(values["layer"], "layer")
+		switch {
+		case values["layer"] == nil:
+			v.report(item, "layer has no name, add layer: <name>")
+		case ok && !token.IsIdentifier(name):
+			v.report(values["layer"], "layer name '%s' is not a valid Go package name", name)
+		case ok && slices.Contains(generatedFolders, name):
+			v.report(values["layer"], "layer name '%s' clashes with the generated %s package", name, name)
+		case ok:
+			if prev, dup := declared[name]; dup {
+				v.report(values["layer"], "layer '%s' is already declared at line %d", name, prev.Line)
+			}
+			declared[name] = values["layer"]
+		}
+		entry.name = name
+
+		if tag, ok := v.scalar(values["tag"], "tag"); ok {
+			entry.tag = tag
+			entry.node = values["tag"]
+			if tag != "" && !slices.Contains(layerTags, tag) {
+				v.report(values["tag"], "unknown tag '%s' of layer '%s'%s", tag, name, suggest(tag, layerTags))
+			}
+		}
+
+		if poller, ok := v.scalar(values["poller"], "poller"); ok {
+			entry.poller = poller
+			switch {
+			case entry.tag != consts.TelebotLayerType:
+				v.report(values["poller"], "poller is a telebot option, layer '%s' uses tag '%s'", name, entry.tag)
+			case poller != consts.TelebotLongPoller && poller != consts.TelebotWebhookPoller:
+				v.report(values["poller"], "unknown poller '%s', use long or webhook", poller)
+			}
+		}
+
+		chain = append(chain, entry)
+		v.chain = append(v.chain, &system.Layer{Name: entry.name, Type: entry.tag, Poller: entry.poller})
+	}
+
+	// transport layers and caches call the next layer, a transport one calls the first non transport layer after it
+	for i, entry := range chain {
+		layer := &system.Layer{Type: entry.tag}
+		switch {
+		case layer.IsTransport():
+			next := slices.IndexFunc(chain[i+1:], func(e layerEntry) bool {
+				return !(&system.Layer{Type: e.tag}).IsTransport()
+			})
+			if next != -1 {
+				continue
+			}
+			if i == len(chain)-1 {
+				v.report(entry.node, "layer '%s' uses tag '%s' but is last in the chain", entry.name, entry.tag)
+			} else {
+				v.report(entry.node, "layer '%s' uses tag '%s' but only transport layers follow it", entry.name, entry.tag)
+			}
+		case entry.tag == consts.RedisLayerType && i == len(chain)-1:
+			v.report(entry.node, "layer '%s' uses tag '%s' but is last in the chain", entry.name, entry.tag)
+		}
+	}
+}
+
+func (v *validator) models(n *yaml.Node) {
+	items := v.sequence(n, "models")
+	if len(items) == 0 && (n.Kind == yaml.SequenceNode || n.Tag == "!!null") {
+		v.report(n, "models is empty, add a model to generate")
+	}
+
+	// the models may use each other as field types, so the names are known before the fields are checked
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == "name" {
+				names = append(names, item.Content[i+1].Value)
+			}
+		}
+	}
+
+	declared := make(map[string]*yaml.Node)
+	for _, item := range items {
+		values := v.mapping(item, "model", modelKeys)
+		if values == nil {
+			continue
+		}
+
+		name, ok := v.scalar(values["name"], "model name")
+		switch {
+		case values["name"] == nil:
+			v.report(item, "model has no name, add name: <Name>")
+		case ok && (!token.IsIdentifier(name) || !token.IsExported(name)):
+			v.report(values["name"], "model name '%s' is not an exported Go identifier", name)
+		case ok:
+			if prev, dup := declared[name]; dup {
+				v.report(values["name"], "model '%s' is already declared at line %d", name, prev.Line)
+			}
+			declared[name] = values["name"]
+		}
+
+		if n := values["fields"]; n != nil {
+			v.fields(n, name, names)
+		}
+		if n := values["methods"]; n != nil {
+			v.methods(n, name)
+		}
+	}
+}
+
+func (v *validator) fields(n *yaml.Node, model string, models []string) {
+	declared := make(map[string]*yaml.Node)
+	for _, item := range v.sequence(n, "fields of model '"+model+"'") {
+		values := v.mapping(item, "field", fieldKeys)
+		if values == nil {
+			continue
+		}
+
+		name, ok := v.scalar(values["name"], "field name")
+		switch {
+		case values["name"] == nil:
+			v.report(item, "field of model '%s' has no name", model)
+		case ok && (!token.IsIdentifier(name) || !token.IsExported(name)):
+			v.report(values["name"], "field name '%s' of model '%s' is not an exported Go identifier", name, model)
+		case ok:
+			if prev, dup := declared[name]; dup {
+				v.report(values["name"], "field '%s' of model '%s' is already declared at line %d", name, model, prev.Line)
+			}
+			declared[name] = values["name"]
+		}
+
+		typ, ok := v.scalar(values["type"], "field type")
+		switch {
+		case values["type"] == nil:
+			v.report(item, "field '%s' of model '%s' has no type", name, model)
+		case ok:
+			if err := checkFieldType(typ, models, v.hasMongo()); err != nil {
+				v.report(values["type"], "type '%s' of field '%s' is invalid: %s", typ, name, err.Error())
+			}
+		}
+
+		if n := values["tags"]; n != nil {
+			for _, tag := range v.sequence(n, "tags of field '"+name+"'") {
+				tagValues := v.mapping(tag, "tag", tagKeys)
+				if tagValues == nil {
+					continue
+				}
+				if key, ok := v.scalar(tagValues["key"], "tag key"); tagValues["key"] == nil || (ok && key == "") {
+					v.report(tag, "tag of field '%s' has no key", name)
+				}
+				v.scalar(tagValues["val"], "tag val")
+			}
+		}
+	}
+}
+
+func (v *validator) methods(n *yaml.Node, model string) {
+	declared := make(map[system.MethodType]*yaml.Node)
+	for _, item := range v.sequence(n, "methods of model '"+model+"'") {
+		name, ok := v.scalar(item, "method")
+		if !ok {
+			continue
+		}
+
+		method := system.MethodType(name)
+		if name == "" || !token.IsIdentifier(method.String()) {
+			v.report(item, "method '%s' of model '%s' is not a valid Go identifier", name, model)
+			continue
+		}
+		if prev, dup := declared[method.Lower()]; dup {
+			v.report(item, "method '%s' of model '%s' is already declared at line %d", name, model, prev.Line)
+		}
+		declared[method.Lower()] = item
+	}
+}
+
+func (v *validator) config(n *yaml.Node) {
+	declared := make(map[string]*yaml.Node)
+	for _, item := range v.sequence(n, "config") {
+		values := v.mapping(item, "config field", configFieldKeys)
+		if values == nil {
+			continue
+		}
+
+		var f system.ConfigField
+		name, ok := v.scalar(values["name"], "config field name")
+		switch {
+		case values["name"] == nil:
+			v.report(item, "config field has no name")
+			continue
+		case !ok:
+			continue
+		case !token.IsIdentifier(name) || !token.IsExported(name):
+			v.report(values["name"], "config field name '%s' is not an exported Go identifier", name)
+		default:
+			if prev, dup := declared[name]; dup {
+				v.report(values["name"], "config field '%s' is already declared at line %d", name, prev.Line)
+			}
+			declared[name] = values["name"]
+		}
+		f.Name = name
+		v.configFields = append(v.configFields, name)
+
+		f.Type = system.ConfigString
+		if typ, ok := v.scalar(values["type"], "config field type"); ok && typ != "" {
+			f.Type = typ
+		}
+		f.Default, _ = v.scalar(values["default"], "config field default")
+		v.scalar(values["description"], "config field description")
+
+		if n := values["required"]; n != nil {
+			if _, ok := v.scalar(n, "required"); ok && n.Tag != "!!bool" {
+				v.report(n, "required must be true or false, got '%s'", n.Value)
+			}
+		}
+
+		if err := gen.CheckConfigField(f); err != nil {
+			// a known type fails on its default
+			at := values["type"]
+			if slices.Contains(configTypes, f.Type) {
+				at = values["default"]
+			}
+			v.report(at, "%s", err.Error())
+		}
+	}
+}
+
+func (v *validator) environments(n *yaml.Node) {
+	if n.Kind != yaml.MappingNode {
+		v.report(n, "environments must be a mapping of environment names")
+		return
+	}
+
+	// an override sets a field added by the layers or a field of the config
+	known := append((&LayerController{Layers: v.chain}).layerConfig(), v.configFields...)
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		env, overrides := n.Content[i], n.Content[i+1]
+		if env.Value == "" || strings.ContainsAny(env.Value, `/\. `) {
+			v.report(env, "environment name '%s' can't be used in a file name", env.Value)
+		}
+
+		if overrides.Kind != yaml.MappingNode {
+			v.report(overrides, "environment '%s' must be a mapping of config field names", env.Value)
+			continue
+		}
+		for j := 0; j+1 < len(overrides.Content); j += 2 {
+			name := overrides.Content[j]
+			if !slices.Contains(known, name.Value) {
+				v.report(name, "environment '%s' overrides unknown config field '%s'%s",
+					env.Value, name.Value, suggest(name.Value, known))
+			}
+			v.scalar(overrides.Content[j+1], "override of "+name.Value)
+		}
+	}
+}
+
+// hasMongo reports whether a layer uses the mongodb tag, the id and object types are generated only for it
+func (v *validator) hasMongo() bool {
+	return slices.ContainsFunc(v.chain, func(layer *system.Layer) bool {
+		return layer.Type == consts.MongoLayerType
+	})
+}
+
+// mapping reports the unknown and repeated keys of the node and returns its values by key
+func (v *validator) mapping(n *yaml.Node, what string, known []string) map[string]*yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		v.report(n, "%s must be a mapping", what)
+		return nil
+	}
+
+	values := make(map[string]*yaml.Node, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if !slices.Contains(known, key.Value) {
+			v.report(key, "unknown key '%s' in %s%s", key.Value, what, suggest(key.Value, known))
+			continue
+		}
+		if _, ok := values[key.Value]; ok {
+			v.report(key, "key '%s' is repeated in %s", key.Value, what)
+		}
+		values[key.Value] = value
+	}
+
+	return values
+}
+
+func (v *validator) sequence(n *yaml.Node, what string) []*yaml.Node {
+	if n.Kind != yaml.SequenceNode {
+		// a key with no value is an empty list
+		if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+			return nil
+		}
+		v.report(n, "%s must be a list", what)
+		return nil
+	}
+
+	return n.Content
+}
+
+// scalar returns the value of the node, false when the node is missing or is not a plain value
+func (v *validator) scalar(n *yaml.Node, what string) (string, bool) {
+	if n == nil {
+		return "", false
+	}
+	if n.Kind != yaml.ScalarNode {
+		v.report(n, "%s must be a single value", what)
+		return "", false
+	}
+
+	return n.Value, true
+}
+
+// checkFieldType parses the type as a go type, every type name must be predeclared, a model or a package one,
+// the id and object types need a mongodb layer
+func checkFieldType(typ string, models []string, mongo bool) error {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return fmt.Errorf("not a Go type")
+	}
+
+	known := append(slices.Clone(goTypes), models...)
+	if mongo {
+		known = append(known, mongoTypes...)
+	}
+
+	var unknown string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// package types like time.Time are left to the compiler
+			return false
+		case *ast.Ident:
+			if unknown == "" && !slices.Contains(known, n.Name) {
+				unknown = n.Name
+			}
+		case *ast.BasicLit, *ast.CallExpr, *ast.BinaryExpr, *ast.UnaryExpr, *ast.FuncLit, *ast.CompositeLit:
+			if unknown == "" {
+				unknown = typ
+			}
+			return false
+		}
+		return true
+	})
+
+	if !mongo && slices.Contains(mongoTypes, unknown) {
+		return fmt.Errorf("%s is generated only for the mongodb layers, add a layer with tag mongodb", unknown)
+	}
+	if unknown != "" {
+		return fmt.Errorf("unknown type %s%s", unknown, suggest(unknown, known))
+	}
+
+	return nil
+}
+
+// suggest returns a hint with the closest known word, empty when no word is close enough
+func suggest(word string, known []string) string {
+	best, bestDistance := "", 3
+	for _, k := range known {
+		if d := distance(word, k); d < bestDistance {
+			best, bestDistance = k, d
+		}
+	}
+
+	if best == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(", did you mean '%s'?", best)
+}
+
+// distance is the levenshtein distance of the words
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+
+	return prev[len(rb)]
+}
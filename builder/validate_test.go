@@ -0,0 +1,214 @@
+package builder
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want []Diagnostic
+	}{
+		{
+			name: "valid",
+			spec: `project_name: wiz
+layers:
+  - layer: controller
+    tag: http
+  - layer: service
+  - layer: repository
+    tag: postgres
+models:
+  - name: User
+    fields:
+      - name: Username
+        type: string
+      - name: Cars
+        type: "[]Car"
+    methods: [Create, Read]
+  - name: Car
+    fields:
+      - name: Brand
+        type: string
+`,
+		},
+		{
+			name: "empty",
+			spec: ``,
+			want: []Diagnostic{{Line: 1, Column: 1, Message: "the spec is empty"}},
+		},
+		{
+			name: "unknown key",
+			spec: `project_name: wiz
+layer:
+  - layer: service
+`,
+			want: []Diagnostic{
+				{Line: 1, Column: 1, Message: "the spec has no models, add models: with a model to generate"},
+				{Line: 2, Column: 1, Message: "unknown key 'layer' in the spec, did you mean 'layers'?"},
+			},
+		},
+		{
+			name: "layers",
+			spec: `layers:
+  - layer: controller
+    tag: htp
+  - layer: models
+  - layer: service
+    poller: long
+  - layer: service
+`,
+			want: []Diagnostic{
+				{Line: 1, Column: 1, Message: "the spec has no models, add models: with a model to generate"},
+				{Line: 3, Column: 10, Message: "unknown tag 'htp' of layer 'controller', did you mean 'http'?"},
+				{Line: 4, Column: 12, Message: "layer name 'models' clashes with the generated models package"},
+				{Line: 6, Column: 13, Message: "poller is a telebot option, layer 'service' uses tag ''"},
+				{Line: 7, Column: 12, Message: "layer 'service' is already declared at line 5"},
+			},
+		},
+		{
+			name: "transport last",
+			spec: `layers:
+  - layer: service
+  - layer: controller
+    tag: http
+`,
+			want: []Diagnostic{
+				{Line: 1, Column: 1, Message: "the spec has no models, add models: with a model to generate"},
+				{Line: 4, Column: 10, Message: "layer 'controller' uses tag 'http' but is last in the chain"},
+			},
+		},
+		{
+			name: "models",
+			spec: `models:
+  - name: user
+    fields:
+      - name: Age
+        type: strng
+      - name: Age
+        type: int
+    methods:
+      - Create
+      - create
+      - "Note:string"
+  - fields: []
+`,
+			want: []Diagnostic{
+				{Line: 2, Column: 11, Message: "model name 'user' is not an exported Go identifier"},
+				{Line: 5, Column: 15, Message: "type 'strng' of field 'Age' is invalid: unknown type strng, did you mean 'string'?"},
+				{Line: 6, Column: 15, Message: "field 'Age' of model 'user' is already declared at line 4"},
+				{Line: 10, Column: 9, Message: "method 'create' of model 'user' is already declared at line 9"},
+				{Line: 11, Column: 9, Message: "method 'Note:string' of model 'user' is not a valid Go identifier"},
+				{Line: 12, Column: 5, Message: "model has no name, add name: <Name>"},
+			},
+		},
+		{
+			name: "config",
+			spec: `config_format: xml
+config:
+  - name: Timeout
+    type: duration
+    default: soon
+  - name: Retries
+    type: float
+environments:
+  prod/eu:
+    Timeout: 1s
+`,
+			want: []Diagnostic{
+				{Line: 1, Column: 1, Message: "the spec has no models, add models: with a model to generate"},
+				{Line: 1, Column: 16, Message: "unknown config format 'xml', use json, yaml or toml"},
+				{Line: 5, Column: 14, Message: "invalid default soon of config field Timeout: time: invalid duration \"soon\""},
+				{Line: 7, Column: 11, Message: "unknown type float of config field Retries, use string, int, bool, duration or []string"},
+				{Line: 9, Column: 3, Message: "environment name 'prod/eu' can't be used in a file name"},
+			},
+		},
+		{
+			name: "mongo types",
+			spec: `layers:
+  - layer: service
+  - layer: repository
+    tag: postgres
+models:
+  - name: User
+    fields:
+      - name: ID
+        type: id
+      - name: Meta
+        type: "[]object"
+`,
+			want: []Diagnostic{
+				{Line: 9, Column: 15, Message: "type 'id' of field 'ID' is invalid: id is generated only for the mongodb layers, add a layer with tag mongodb"},
+				{Line: 11, Column: 15, Message: "type '[]object' of field 'Meta' is invalid: object is generated only for the mongodb layers, add a layer with tag mongodb"},
+			},
+		},
+		{
+			name: "mongo types with mongodb",
+			spec: `layers:
+  - layer: service
+  - layer: repository
+    tag: mongodb
+models:
+  - name: User
+    fields:
+      - name: ID
+        type: id
+      - name: Meta
+        type: object
+`,
+		},
+		{
+			name: "no models",
+			spec: `project_name: wiz
+models: []
+`,
+			want: []Diagnostic{
+				{Line: 2, Column: 9, Message: "models is empty, add a model to generate"},
+			},
+		},
+		{
+			name: "environments",
+			spec: `layers:
+  - layer: controller
+    tag: http
+  - layer: service
+config:
+  - name: Timeout
+    type: duration
+environments:
+  prod:
+    HttpPort: "80"
+    Timeout: 1s
+    Timout: 2s
+    MongoUri: mongodb://db
+models:
+  - name: User
+`,
+			want: []Diagnostic{
+				{Line: 12, Column: 5, Message: "environment 'prod' overrides unknown config field 'Timout', did you mean 'Timeout'?"},
+				{Line: 13, Column: 5, Message: "environment 'prod' overrides unknown config field 'MongoUri'"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Validate([]byte(tt.spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("got\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNotYAML(t *testing.T) {
+	_, err := Validate([]byte("layers: [\n"))
+	if err == nil {
+		t.Fatal("expected an error for a spec that is not yaml")
+	}
+}
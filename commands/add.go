@@ -0,0 +1,379 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"gopkg.in/yaml.v3"
+	"gowizard/builder"
+	"gowizard/builder/model/system"
+	"gowizard/responses"
+	"gowizard/util"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// add command kinds
+const (
+	addModel  = "model"
+	addMethod = "method"
+)
+
+type AddCommand struct {
+	kind     string
+	filepath string
+
+	model   string
+	fields  [][2]string
+	methods []string
+}
+
+var _ Command = &AddCommand{}
+
+func NewAddCommand(args []string) (Command, error) {
+	command := &AddCommand{filepath: defaultInitFile}
+	if len(args) < 3 {
+		return command, responses.WrongArgs
+	}
+	command.kind = args[1]
+
+	var positional []string
+	for i := 2; i < len(args); i++ {
+		switch args[i] {
+		case "--field", "--methods", "--spec":
+			if i+1 == len(args) {
+				return command, responses.WrongArgs
+			}
+
+			value := args[i+1]
+			switch args[i] {
+			case "--field":
+				fields, err := parseInitFields(value)
+				if err != nil {
+					return command, err
+				}
+				command.fields = append(command.fields, fields...)
+			case "--methods":
+				methods, err := parseMethods(value)
+				if err != nil {
+					return command, err
+				}
+				command.methods = append(command.methods, methods...)
+			default:
+				command.filepath = value
+			}
+			i++
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+
+	switch {
+	case command.kind == addModel && len(positional) == 1:
+		command.model = util.MakePublicName(positional[0])
+		if len(command.fields) == 0 {
+			return command, errors.New("a model needs a field, add --field Name:type")
+		}
+		if len(command.methods) == 0 {
+			command.methods = initModel.Methods
+		}
+	case command.kind == addMethod && len(positional) == 2:
+		command.model = util.MakePublicName(positional[0])
+		methods, err := parseMethods(positional[1])
+		if err != nil {
+			return command, err
+		}
+		command.methods = methods
+		if len(command.methods) != 1 {
+			return command, responses.WrongArgs
+		}
+	default:
+		return command, responses.WrongArgs
+	}
+
+	return command, nil
+}
+
+// parseMethods reads methods such as create,read
+func parseMethods(value string) ([]string, error) {
+	var methods []string
+	for _, method := range strings.Split(value, ",") {
+		method = strings.TrimSpace(method)
+		if method == "" {
+			continue
+		}
+		if name := util.MakePublicName(method); token.IsIdentifier(name) {
+			methods = append(methods, name)
+			continue
+		}
+
+		return nil, fmt.Errorf("method %s is not a valid Go identifier", method)
+	}
+
+	if len(methods) == 0 {
+		return nil, errors.New("a model needs a method")
+	}
+
+	return methods, nil
+}
+
+func (cmd *AddCommand) Run() (string, error) {
+	spec, err := os.ReadFile(cmd.filepath)
+	if err != nil {
+		return "", fmt.Errorf("could not read file: %w", err)
+	}
+
+	err = validateSpec(cmd.filepath, spec)
+	if err != nil {
+		return "", fmt.Errorf("invalid spec: %w", err)
+	}
+
+	var doc yaml.Node
+	err = yaml.Unmarshal(spec, &doc)
+	if err != nil {
+		return "", fmt.Errorf("could not parse file: %w", err)
+	}
+
+	var updated []byte
+	if cmd.kind == addModel {
+		updated, err = cmd.addModel(spec, &doc)
+	} else {
+		updated, err = cmd.addMethod(spec, &doc)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = validateSpec(cmd.filepath, updated)
+	if err != nil {
+		return "", fmt.Errorf("invalid change: %w", err)
+	}
+
+	var previous, next builder.Builder
+	err = yaml.Unmarshal(spec, &previous)
+	if err != nil {
+		return "", fmt.Errorf("could not parse file: %w", err)
+	}
+	err = yaml.Unmarshal(updated, &next)
+	if err != nil {
+		return "", fmt.Errorf("could not parse change: %w", err)
+	}
+
+	err = next.Patch(&previous)
+	if err != nil {
+		return "", fmt.Errorf("could not patch project: %w", err)
+	}
+
+	err = os.WriteFile(cmd.filepath, updated, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("unable to write %s: %w", cmd.filepath, err)
+	}
+
+	added := fmt.Sprintf("model %s", cmd.model)
+	if cmd.kind == addMethod {
+		added = fmt.Sprintf("method %s of model %s", cmd.methods[0], cmd.model)
+	}
+
+	return fmt.Sprintf("%s added to %s\n%s", added, cmd.filepath, strings.Join(next.Report, "\n")), nil
+}
+
+// addModel appends the model to the models of the spec
+func (cmd *AddCommand) addModel(spec []byte, doc *yaml.Node) ([]byte, error) {
+	root := doc.Content[0]
+	models := mappingValue(root, "models")
+	if models != nil && findModel(models, cmd.model) != nil {
+		return nil, fmt.Errorf("model %s already exists", cmd.model)
+	}
+
+	mdl := addModelDTO{Name: cmd.model, Methods: cmd.methods}
+	for _, f := range cmd.fields {
+		mdl.Fields = append(mdl.Fields, addFieldDTO{Name: f[0], Type: f[1]})
+	}
+
+	// the model is written after the last one, so the comments and the layout of the spec stay
+	if models != nil && isBlockSequence(models) {
+		last := models.Content[len(models.Content)-1]
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		err := enc.Encode(mdl)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode model: %w", err)
+		}
+
+		indent := strings.Repeat(" ", last.Column-3)
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		for i := range lines {
+			prefix := indent + "  "
+			if i == 0 {
+				prefix = indent + "- "
+			}
+			lines[i] = prefix + lines[i]
+		}
+
+		return insertLines(spec, itemEnd(spec, last), lines), nil
+	}
+
+	var item yaml.Node
+	err := item.Encode(mdl)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode model: %w", err)
+	}
+
+	if models == nil {
+		models = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "models"}, models)
+	}
+	models.Kind, models.Tag, models.Value = yaml.SequenceNode, "", ""
+	models.Content = append(models.Content, &item)
+
+	return encodeSpec(doc)
+}
+
+// addMethod appends the method to the methods of the model in the spec
+func (cmd *AddCommand) addMethod(spec []byte, doc *yaml.Node) ([]byte, error) {
+	root := doc.Content[0]
+	models := mappingValue(root, "models")
+	var mdl *yaml.Node
+	if models != nil {
+		mdl = findModel(models, cmd.model)
+	}
+	if mdl == nil {
+		return nil, fmt.Errorf("model %s doesn't exist, add it with gowizard add model", cmd.model)
+	}
+
+	method := cmd.methods[0]
+	methods := mappingValue(mdl, "methods")
+	if methods != nil {
+		for _, m := range methods.Content {
+			if system.MethodType(m.Value).Lower() == system.MethodType(method).Lower() {
+				return nil, fmt.Errorf("model %s already has method %s", cmd.model, method)
+			}
+		}
+	}
+
+	// the method is written after the last one in the same style
+	if methods != nil && isBlockSequence(methods) {
+		last := methods.Content[len(methods.Content)-1]
+		value := method
+		switch last.Style {
+		case yaml.DoubleQuotedStyle:
+			value = strconv.Quote(method)
+		case yaml.SingleQuotedStyle:
+			value = "'" + method + "'"
+		}
+
+		return insertLines(spec, itemEnd(spec, last), []string{strings.Repeat(" ", last.Column-3) + "- " + value}), nil
+	}
+
+	if methods == nil {
+		methods = &yaml.Node{Kind: yaml.SequenceNode}
+		mdl.Content = append(mdl.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "methods"}, methods)
+	}
+	methods.Kind, methods.Tag, methods.Value = yaml.SequenceNode, "", ""
+	methods.Content = append(methods.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: method})
+
+	return encodeSpec(doc)
+}
+
+type addModelDTO struct {
+	Name    string        `yaml:"name"`
+	Fields  []addFieldDTO `yaml:"fields"`
+	Methods []string      `yaml:"methods"`
+}
+
+type addFieldDTO struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func findModel(models *yaml.Node, name string) *yaml.Node {
+	for _, mdl := range models.Content {
+		if n := mappingValue(mdl, "name"); n != nil && n.Value == name {
+			return mdl
+		}
+	}
+
+	return nil
+}
+
+func isBlockSequence(n *yaml.Node) bool {
+	return n.Kind == yaml.SequenceNode && n.Style&yaml.FlowStyle == 0 && len(n.Content) > 0
+}
+
+// itemEnd is the last line of the sequence item, counted from 1. The lines indented deeper than
+// its dash belong to it, so a block scalar or a comment ending the item is not split
+func itemEnd(text []byte, item *yaml.Node) int {
+	dash := item.Column - 3
+	lines := strings.Split(string(text), "\n")
+	end := item.Line
+	for i := item.Line; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		if trimmed == "" {
+			continue
+		}
+		if len(lines[i])-len(trimmed) <= dash {
+			break
+		}
+		end = i + 1
+	}
+
+	return end
+}
+
+// insertLines writes the lines after the line of the text, counted from 1
+func insertLines(text []byte, line int, lines []string) []byte {
+	all := strings.SplitAfter(string(text), "\n")
+	if all[len(all)-1] == "" {
+		all = all[:len(all)-1]
+	}
+	if line > len(all) {
+		line = len(all)
+	}
+	if line > 0 && !strings.HasSuffix(all[line-1], "\n") {
+		all[line-1] += "\n"
+	}
+
+	inserted := strings.Join(lines, "\n") + "\n"
+	return []byte(strings.Join(all[:line], "") + inserted + strings.Join(all[line:], ""))
+}
+
+func encodeSpec(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err := enc.Encode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode spec: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (cmd *AddCommand) GetHelp() string {
+	return fmt.Sprintf(`Usage: gowizard add model <Name> --field <Name:type> [--field <Name:type>] [--methods <create,read>] [--spec <filepath>]
+       gowizard add method <Model> <Method> [--spec <filepath>]
+
+  --field    a field of the model, may be repeated
+  --methods  the methods of the model, create,read,update,delete by default
+  --spec     the spec to update, %s by default
+
+The spec is updated and only the files of the change are generated, the change is merged into the
+files edited by hand, a file it conflicts with gets the generated one next to it as <file>.gowizard.new`, defaultInitFile)
+}
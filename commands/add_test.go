@@ -0,0 +1,387 @@
+package commands
+
+import (
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+func TestAddModelSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{
+			name: "block",
+			spec: `project_name: wiz
+models:
+  - name: User
+    fields:
+      - name: Username
+        type: string
+    methods:
+      - Read
+`,
+			want: `project_name: wiz
+models:
+  - name: User
+    fields:
+      - name: Username
+        type: string
+    methods:
+      - Read
+  - name: Order
+    fields:
+      - name: Total
+        type: float64
+    methods:
+      - Create
+`,
+		},
+		{
+			name: "comments",
+			spec: `project_name: wiz # the module
+models:
+  # the users of the shop
+  - name: User
+    fields:
+      - name: Username
+        type: string
+    methods:
+      - Read
+      # - Delete
+#environments:
+#  prod: {}
+`,
+			want: `project_name: wiz # the module
+models:
+  # the users of the shop
+  - name: User
+    fields:
+      - name: Username
+        type: string
+    methods:
+      - Read
+      # - Delete
+  - name: Order
+    fields:
+      - name: Total
+        type: float64
+    methods:
+      - Create
+#environments:
+#  prod: {}
+`,
+		},
+		{
+			name: "block scalar",
+			spec: `models:
+  - name: User
+    fields:
+      - name: Username
+        type: string
+        tags:
+          - key: validate
+            val: |
+              required
+
+    methods: [Read]
+layers:
+  - layer: service
+`,
+			want: `models:
+  - name: User
+    fields:
+      - name: Username
+        type: string
+        tags:
+          - key: validate
+            val: |
+              required
+
+    methods: [Read]
+  - name: Order
+    fields:
+      - name: Total
+        type: float64
+    methods:
+      - Create
+layers:
+  - layer: service
+`,
+		},
+		{
+			name: "unindented",
+			spec: `models:
+- name: User
+  fields:
+  - name: Username
+    type: string
+layers: []
+`,
+			want: `models:
+- name: User
+  fields:
+  - name: Username
+    type: string
+- name: Order
+  fields:
+    - name: Total
+      type: float64
+  methods:
+    - Create
+layers: []
+`,
+		},
+		{
+			name: "flow",
+			spec: `project_name: wiz
+models: [{name: User, fields: [{name: Username, type: string}], methods: [Read]}]
+`,
+			want: `project_name: wiz
+models: [{name: User, fields: [{name: Username, type: string}], methods: [Read]}, {name: Order, fields: [{name: Total, type: float64}], methods: [Create]}]
+`,
+		},
+		{
+			name: "no models",
+			spec: `project_name: wiz
+`,
+			want: `project_name: wiz
+models:
+  - name: Order
+    fields:
+      - name: Total
+        type: float64
+    methods:
+      - Create
+`,
+		},
+	}
+
+	cmd := &AddCommand{kind: addModel, model: "Order", fields: [][2]string{{"Total", "float64"}}, methods: []string{"Create"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			err := yaml.Unmarshal([]byte(tt.spec), &doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := cmd.addModel([]byte(tt.spec), &doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddModelExists(t *testing.T) {
+	spec := []byte("models:\n  - name: Order\n")
+	var doc yaml.Node
+	err := yaml.Unmarshal(spec, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &AddCommand{kind: addModel, model: "Order", fields: [][2]string{{"Total", "float64"}}, methods: []string{"Create"}}
+	_, err = cmd.addModel(spec, &doc)
+	if err == nil {
+		t.Fatal("expected an error for an existing model")
+	}
+}
+
+func TestAddMethodSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "double quoted",
+			spec: `models:
+  - name: User
+    methods:
+      - "Create"
+      - "Read"
+  - name: Car
+    methods:
+      - "Read"
+`,
+			want: `models:
+  - name: User
+    methods:
+      - "Create"
+      - "Read"
+      - "Ban"
+  - name: Car
+    methods:
+      - "Read"
+`,
+		},
+		{
+			name: "single quoted with comment",
+			spec: `models:
+  - name: User
+    methods:
+      - 'Read' # the list
+      # - 'Delete'
+`,
+			want: `models:
+  - name: User
+    methods:
+      - 'Read' # the list
+      - 'Ban'
+      # - 'Delete'
+`,
+		},
+		{
+			name: "flow",
+			spec: `models:
+  - name: User
+    methods: [Read]
+`,
+			want: `models:
+  - name: User
+    methods: [Read, Ban]
+`,
+		},
+		{
+			name: "no methods",
+			spec: `models:
+  - name: User
+`,
+			want: `models:
+  - name: User
+    methods:
+      - Ban
+`,
+		},
+		{
+			name: "existing method",
+			spec: `models:
+  - name: User
+    methods: [ban]
+`,
+			wantErr: true,
+		},
+		{
+			name: "unknown model",
+			spec: `models:
+  - name: Car
+`,
+			wantErr: true,
+		},
+	}
+
+	cmd := &AddCommand{kind: addMethod, model: "User", methods: []string{"Ban"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			err := yaml.Unmarshal([]byte(tt.spec), &doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := cmd.addMethod([]byte(tt.spec), &doc)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		line  int
+		lines []string
+		want  string
+	}{
+		{
+			name:  "middle",
+			text:  "a\nb\nc\n",
+			line:  2,
+			lines: []string{"x", "y"},
+			want:  "a\nb\nx\ny\nc\n",
+		},
+		{
+			name:  "end",
+			text:  "a\nb\n",
+			line:  2,
+			lines: []string{"x"},
+			want:  "a\nb\nx\n",
+		},
+		{
+			name:  "no trailing newline",
+			text:  "a\nb",
+			line:  2,
+			lines: []string{"x"},
+			want:  "a\nb\nx\n",
+		},
+		{
+			name:  "past the end",
+			text:  "a\n",
+			line:  10,
+			lines: []string{"x"},
+			want:  "a\nx\n",
+		},
+		{
+			name:  "start",
+			text:  "a\n",
+			line:  0,
+			lines: []string{"x"},
+			want:  "x\na\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(insertLines([]byte(tt.text), tt.line, tt.lines))
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBlockSequence(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want bool
+	}{
+		{name: "block", spec: "- a\n- b\n", want: true},
+		{name: "flow", spec: "[a, b]\n", want: false},
+		{name: "empty flow", spec: "[]\n", want: false},
+		{name: "mapping", spec: "a: b\n", want: false},
+		{name: "scalar", spec: "a\n", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			err := yaml.Unmarshal([]byte(tt.spec), &doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := isBlockSequence(doc.Content[0]); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
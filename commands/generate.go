@@ -1,28 +1,67 @@
 package commands
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"gopkg.in/yaml.v3"
 	"gowizard/builder"
+	"gowizard/consts"
 	"gowizard/responses"
 	"io"
 	"os"
+	"strings"
 )
 
 type GenerateCommand struct {
 	filepath string
+	demo     bool
+	dryRun   bool
+	// onConflict settles every conflict, they are asked when it is empty
+	onConflict builder.Resolution
+
+	in      io.Reader
+	out     io.Writer
+	answers *bufio.Scanner
 }
 
 var _ Command = &GenerateCommand{}
 
 func NewGenerateCommand(args []string) (Command, error) {
-	command := &GenerateCommand{}
+	command := &GenerateCommand{
+		in:  os.Stdin,
+		out: os.Stdout,
+	}
 	if len(args) < 2 {
 		return command, responses.WrongArgs
 	}
 
-	command.filepath = args[1]
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--demo":
+			command.demo = true
+		case "--dry-run":
+			command.dryRun = true
+		case "--on-conflict":
+			if i+1 == len(args) {
+				return command, responses.WrongArgs
+			}
+			command.onConflict = builder.Resolution(args[i+1])
+			i++
+		default:
+			command.filepath = args[i]
+		}
+	}
+
+	switch command.onConflict {
+	case "", builder.ResolveSkip, builder.ResolveOverwrite, builder.ResolveNew:
+	default:
+		return command, fmt.Errorf("unknown conflict resolution %s, use skip, overwrite or new", command.onConflict)
+	}
+
+	if command.filepath == "" {
+		return command, responses.WrongArgs
+	}
 
 	return command, nil
 }
@@ -39,11 +78,19 @@ func (cmd *GenerateCommand) Run() (string, error) {
 		return "", fmt.Errorf("could not read file: %w", err)
 	}
 
+	err = validateSpec(cmd.filepath, cfgRaw)
+	if err != nil {
+		return "", fmt.Errorf("invalid spec: %w", err)
+	}
+
 	var b builder.Builder
 	err = yaml.Unmarshal(cfgRaw, &b)
 	if err != nil {
 		return "", fmt.Errorf("could not parse file: %w", err)
 	}
+	b.Demo = cmd.demo
+	b.DryRun = cmd.dryRun
+	b.Resolve = cmd.resolve
 
 	r, _ := json.Marshal(b)
 
@@ -52,9 +99,60 @@ func (cmd *GenerateCommand) Run() (string, error) {
 		return "", fmt.Errorf("could not generate code: %w", err)
 	}
 
+	if cmd.dryRun {
+		return b.Plan()
+	}
+
+	if len(b.Report) > 0 {
+		return string(r) + "\n\n" + strings.Join(b.Report, "\n"), nil
+	}
+
 	return string(r), nil
 }
 
+// resolve asks how to write a file edited since the last generation, it is skipped with no answer
+func (cmd *GenerateCommand) resolve(path string) (builder.Resolution, error) {
+	if cmd.onConflict != "" {
+		return cmd.onConflict, nil
+	}
+
+	if cmd.answers == nil {
+		cmd.answers = bufio.NewScanner(cmd.in)
+	}
+
+	for {
+		fmt.Fprintf(cmd.out, "%s was edited since the last generation, [s]kip, [o]verwrite or write [n]ew %s%s? [s]: ",
+			path, path, consts.LockNewSuffix)
+		if !cmd.answers.Scan() {
+			return builder.ResolveSkip, cmd.answers.Err()
+		}
+
+		switch strings.ToLower(strings.TrimSpace(cmd.answers.Text())) {
+		case "", "s", "skip":
+			return builder.ResolveSkip, nil
+		case "o", "overwrite":
+			return builder.ResolveOverwrite, nil
+		case "n", "new":
+			return builder.ResolveNew, nil
+		}
+	}
+}
+
 func (cmd *GenerateCommand) GetHelp() string {
-	return `Usage: gowizard generate <filepath>`
+	return `Usage: gowizard generate <filepath> [--demo] [--dry-run] [--on-conflict skip|overwrite|new]
+
+  --demo         generate in-memory repositories in place of the databases
+  --dry-run      print the files that would be new, changed, conflicting or unchanged with a diff of the
+                 changed ones, nothing is written and no go tool is run
+  --on-conflict  write the files edited since the last generation in this way instead of asking,
+                 new writes the generated file next to the edited one as <file>.gowizard.new, a skipped
+                 or a new file is a conflict again on the next generation until it is overwritten
+
+The method bodies between the gowizard:begin and gowizard:end comments edited by hand are kept on regeneration.
+The files generated are recorded in .gowizard.lock, a file edited outside those comments is a conflict,
+unsafe: true in the spec overwrites the conflicts. The lock holds the hash of the spec too, a spec unchanged
+since the last generation with no conflict left writes nothing, remove the lock to generate again.
+
+A grpc layer needs protoc, protoc-gen-go and protoc-gen-go-grpc in PATH to compile the proto files,
+they are looked up before anything is written. The http, echo and fiber layers need swag for the docs.`
 }
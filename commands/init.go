@@ -0,0 +1,314 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/token"
+	"gowizard/builder"
+	"gowizard/consts"
+	"gowizard/responses"
+	"gowizard/util"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultInitFile   = "gowizard.yaml"
+	defaultInitPreset = "rest-postgres"
+
+	transportTagsComment  = "http (gin) | nethttp | echo | fiber | grpc | graphql | consumer | cli | telebot | discord | slack"
+	repositoryTagsComment = "postgres | mysql | sqlite | mongodb | memory"
+)
+
+// initPresets are the layers of the starter specs
+var initPresets = map[string][]builder.LayerDTO{
+	"rest-postgres": {
+		{Layer: "controller", Tag: consts.HTTPLayerType},
+		{Layer: "service"},
+		{Layer: "repository", Tag: consts.RepoLayerType},
+	},
+	"telebot-postgres": {
+		{Layer: "controller", Tag: consts.TelebotLayerType},
+		{Layer: "service"},
+		{Layer: "repository", Tag: consts.RepoLayerType},
+	},
+	"grpc-mysql": {
+		{Layer: "controller", Tag: consts.GRPCLayerType},
+		{Layer: "service"},
+		{Layer: "repository", Tag: consts.MySQLLayerType},
+	},
+}
+
+// initModel is the model of the starter spec when the models are not asked
+var initModel = initModelDTO{
+	Name: "User",
+	Fields: [][2]string{
+		{"Username", "string"},
+		{"Age", "uint8"},
+	},
+	Methods: []string{"Create", "Read", "Update", "Delete"},
+}
+
+type initModelDTO struct {
+	Name string
+	// Fields are name and type pairs
+	Fields  [][2]string
+	Methods []string
+}
+
+type InitCommand struct {
+	filepath    string
+	name        string
+	preset      string
+	interactive bool
+
+	in  io.Reader
+	out io.Writer
+}
+
+var _ Command = &InitCommand{}
+
+func NewInitCommand(args []string) (Command, error) {
+	command := &InitCommand{
+		filepath: defaultInitFile,
+		name:     "gowizard",
+		preset:   defaultInitPreset,
+		in:       os.Stdin,
+		out:      os.Stdout,
+	}
+
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--interactive", "-i":
+			command.interactive = true
+		case "--name", "--preset":
+			if i+1 == len(args) {
+				return command, responses.WrongArgs
+			}
+			if args[i] == "--name" {
+				command.name = args[i+1]
+			} else {
+				command.preset = args[i+1]
+			}
+			i++
+		default:
+			command.filepath = args[i]
+		}
+	}
+
+	if _, ok := initPresets[command.preset]; !ok {
+		return command, fmt.Errorf("unknown preset %s, use %s", command.preset, strings.Join(presetNames(), ", "))
+	}
+
+	return command, nil
+}
+
+func (cmd *InitCommand) Run() (string, error) {
+	if _, err := os.Stat(cmd.filepath); err == nil {
+		return "", fmt.Errorf("%s already exists", cmd.filepath)
+	}
+
+	models := []initModelDTO{initModel}
+	if cmd.interactive {
+		var err error
+		models, err = cmd.ask()
+		if err != nil {
+			return "", fmt.Errorf("unable to read answers: %w", err)
+		}
+	}
+
+	spec := initSpec(cmd.name, initPresets[cmd.preset], models)
+
+	// the spec must be accepted by generate, an answer may break the yaml or name an unknown type
+	err := validateSpec(cmd.filepath, []byte(spec))
+	if err != nil {
+		return "", fmt.Errorf("invalid spec: %w", err)
+	}
+
+	err = os.WriteFile(cmd.filepath, []byte(spec), 0o644)
+	if err != nil {
+		return "", fmt.Errorf("unable to write %s: %w", cmd.filepath, err)
+	}
+
+	return fmt.Sprintf("%s written, run gowizard generate %s", cmd.filepath, cmd.filepath), nil
+}
+
+// ask reads the project name, the preset and the models from the input
+func (cmd *InitCommand) ask() ([]initModelDTO, error) {
+	scanner := bufio.NewScanner(cmd.in)
+	read := func(question, def string) (string, error) {
+		fmt.Fprintf(cmd.out, "%s [%s]: ", question, def)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return def, nil
+		}
+
+		answer := strings.TrimSpace(scanner.Text())
+		if answer == "" {
+			return def, nil
+		}
+		return answer, nil
+	}
+
+	var err error
+	cmd.name, err = read("Project name", cmd.name)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		preset, err := read("Preset ("+strings.Join(presetNames(), ", ")+")", cmd.preset)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := initPresets[preset]; ok {
+			cmd.preset = preset
+			break
+		}
+		fmt.Fprintf(cmd.out, "unknown preset %s\n", preset)
+	}
+
+	var models []initModelDTO
+	for {
+		name, err := read("Model name, empty to finish", "")
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			break
+		}
+
+		mdl := initModelDTO{Name: util.MakePublicName(name)}
+		if !token.IsIdentifier(mdl.Name) {
+			fmt.Fprintf(cmd.out, "model %s is not a valid Go identifier\n", name)
+			continue
+		}
+
+		for {
+			answer, err := read("Fields of "+mdl.Name+" as Name:type separated by spaces", "Name:string")
+			if err != nil {
+				return nil, err
+			}
+
+			mdl.Fields, err = parseInitFields(answer)
+			if err == nil {
+				break
+			}
+			fmt.Fprintln(cmd.out, err.Error())
+		}
+
+		for {
+			answer, err := read("Methods of "+mdl.Name, "create,read,update,delete")
+			if err != nil {
+				return nil, err
+			}
+
+			mdl.Methods, err = parseMethods(answer)
+			if err == nil {
+				break
+			}
+			fmt.Fprintln(cmd.out, err.Error())
+		}
+
+		models = append(models, mdl)
+	}
+
+	if len(models) == 0 {
+		return []initModelDTO{initModel}, nil
+	}
+
+	return models, nil
+}
+
+// parseInitFields reads fields such as Name:string Age:uint8
+func parseInitFields(answer string) ([][2]string, error) {
+	var fields [][2]string
+	for _, f := range strings.Fields(answer) {
+		name, typ, ok := strings.Cut(f, ":")
+		if !ok || name == "" || typ == "" {
+			return nil, fmt.Errorf("field %s is not Name:type", f)
+		}
+		if name = util.MakePublicName(name); !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("field %s is not a valid Go identifier", f)
+		}
+		fields = append(fields, [2]string{name, typ})
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("a model needs a field")
+	}
+
+	return fields, nil
+}
+
+// initSpec writes the starter spec, the options left out are written as comments
+func initSpec(name string, layers []builder.LayerDTO, models []initModelDTO) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`project_name: %s
+#unsafe: true # overwrite the files edited since the last generation, listed in .gowizard.lock
+#path: %s
+#config_format: yaml # json (default) | yaml | toml
+#config: # extra config fields, read from the config file or APP_<NAME> variables
+#  - name: RequestTimeout
+#    type: duration # string (default) | int | bool | duration | []string
+#    default: 5s
+#    required: true
+#environments: # config profiles chosen by APP_ENV
+#  prod:
+#    RequestTimeout: 30s
+layers:
+`, util.MakeString(name), util.MakeString(name)))
+
+	for _, layer := range layers {
+		sb.WriteString("  - layer: " + layer.Layer + "\n")
+		switch layer.Layer {
+		case "controller":
+			sb.WriteString("    tag: " + layer.Tag + " # " + transportTagsComment + "\n")
+		case "repository":
+			sb.WriteString("    tag: " + layer.Tag + " # " + repositoryTagsComment + "\n")
+		default:
+			if layer.Tag != "" {
+				sb.WriteString("    tag: " + layer.Tag + "\n")
+			}
+		}
+	}
+
+	sb.WriteString("models:\n")
+	for _, mdl := range models {
+		sb.WriteString("  - name: " + mdl.Name + "\n    fields:\n")
+		for _, f := range mdl.Fields {
+			sb.WriteString(fmt.Sprintf("      - name: %s\n        type: %s\n", f[0], util.MakeString(f[1])))
+		}
+
+		sb.WriteString("    methods:\n")
+		for _, method := range mdl.Methods {
+			sb.WriteString("      - " + util.MakeString(method) + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func presetNames() []string {
+	names := make([]string, 0, len(initPresets))
+	for name := range initPresets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func (cmd *InitCommand) GetHelp() string {
+	return fmt.Sprintf(`Usage: gowizard init [filepath] [--name <project>] [--preset <preset>] [--interactive]
+
+  filepath       the spec to write, %s by default
+  --name         the project name
+  --preset       the layers: %s, %s by default
+  --interactive  ask the project name, the preset and the models`, defaultInitFile, strings.Join(presetNames(), ", "), defaultInitPreset)
+}
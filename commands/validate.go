@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"gowizard/builder"
+	"gowizard/responses"
+	"os"
+	"strings"
+)
+
+type ValidateCommand struct {
+	filepath string
+}
+
+var _ Command = &ValidateCommand{}
+
+func NewValidateCommand(args []string) (Command, error) {
+	command := &ValidateCommand{}
+	if len(args) < 2 {
+		return command, responses.WrongArgs
+	}
+	command.filepath = args[1]
+
+	return command, nil
+}
+
+func (cmd *ValidateCommand) Run() (string, error) {
+	spec, err := os.ReadFile(cmd.filepath)
+	if err != nil {
+		return "", fmt.Errorf("could not read file: %w", err)
+	}
+
+	err = validateSpec(cmd.filepath, spec)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s is valid", cmd.filepath), nil
+}
+
+// validateSpec reports every problem of the spec as file:line:column: message
+func validateSpec(path string, spec []byte) error {
+	diagnostics, err := builder.Validate(spec)
+	if err != nil {
+		return err
+	}
+	if len(diagnostics) == 0 {
+		return nil
+	}
+
+	problems := make([]string, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		problems = append(problems, fmt.Sprintf("%s:%d:%d: %s", path, d.Line, d.Column, d.Message))
+	}
+
+	return fmt.Errorf("%d problem(s) found in %s\n%s", len(diagnostics), path, strings.Join(problems, "\n"))
+}
+
+func (cmd *ValidateCommand) GetHelp() string {
+	return `Usage: gowizard validate <filepath>
+
+  reports every problem of the spec as file:line:column: message, generate runs it first`
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSpec(t *testing.T) {
+	err := validateSpec("wiz.yaml", []byte(`project_name: wiz
+layers:
+  - layer: controller
+    tag: htp
+models:
+  - name: User
+    methods: [Note:string]
+`))
+	if err == nil {
+		t.Fatal("expected the problems of the spec")
+	}
+
+	want := `2 problem(s) found in wiz.yaml
+wiz.yaml:4:10: unknown tag 'htp' of layer 'controller', did you mean 'http'?
+wiz.yaml:7:15: method 'Note:string' of model 'User' is not a valid Go identifier`
+	if err.Error() != want {
+		t.Fatalf("got\n%s\nwant\n%s", err.Error(), want)
+	}
+}
+
+func TestValidateSpecValid(t *testing.T) {
+	err := validateSpec("wiz.yaml", []byte(initSpec("wiz", initPresets[defaultInitPreset], []initModelDTO{initModel})))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateSpecUncommentedPath(t *testing.T) {
+	spec := initSpec("wiz: api", initPresets[defaultInitPreset], []initModelDTO{initModel})
+	err := validateSpec("wiz.yaml", []byte(strings.Replace(spec, "#path:", "path:", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,18 +1,87 @@
 package consts
 
 const (
-	DefaultModelsFolder     = "models"
-	DefaultConfigFolder     = "config"
-	DefaultRouterFolder     = "router"
-	DefaultTelerouterFolder = "telerouter"
+	DefaultModelsFolder        = "models"
+	DefaultConfigFolder        = "config"
+	DefaultRouterFolder        = "router"
+	DefaultTelerouterFolder    = "telerouter"
+	DefaultDiscordRouterFolder = "discordrouter"
+	DefaultSlackRouterFolder   = "slackrouter"
+	DefaultChatFolder          = "chat"
+	DefaultProtoFolder         = "proto"
+	DefaultGRPCServerFolder    = "grpcserver"
+	DefaultBrokerFolder        = "broker"
+	DefaultSubscriberFolder    = "subscriber"
+	DefaultCommandsFolder      = "commands"
 
-	HTTPLayerType    = "http"
-	RepoLayerType    = "postgres"
-	TelebotLayerType = "telebot"
+	// ConfigEnvPrefix starts the environment variables overriding the config fields, e.g. APP_HTTP_PORT
+	ConfigEnvPrefix = "APP_"
+	// ConfigPathEnv and ConfigPathFlag choose the config file in place of config.json
+	ConfigPathEnv  = "APP_CONFIG"
+	ConfigPathFlag = "config"
+	// ConfigProfileEnv names the profile read over the config file, e.g. APP_ENV=prod reads config.prod.json
+	ConfigProfileEnv = "APP_ENV"
+	EnvExampleFile   = ".env.example"
 
-	GinURL = "github.com/gin-gonic/gin"
+	// LockFile records the files of the last generation, a conflicting file may be written next to
+	// the edited one with the LockNewSuffix
+	LockFile      = ".gowizard.lock"
+	LockNewSuffix = ".gowizard.new"
+
+	// the config formats are named as the packages decoding them
+	ConfigFormatJSON = "json"
+	ConfigFormatYAML = "yaml"
+	ConfigFormatTOML = "toml"
+
+	HTTPLayerType     = "http"
+	NetHTTPLayerType  = "nethttp"
+	EchoLayerType     = "echo"
+	FiberLayerType    = "fiber"
+	GRPCLayerType     = "grpc"
+	GraphQLLayerType  = "graphql"
+	RepoLayerType     = "postgres"
+	MySQLLayerType    = "mysql"
+	SQLiteLayerType   = "sqlite"
+	MongoLayerType    = "mongodb"
+	MemoryLayerType   = "memory"
+	TelebotLayerType  = "telebot"
+	DiscordLayerType  = "discord"
+	SlackLayerType    = "slack"
+	ConsumerLayerType = "consumer"
+	CLILayerType      = "cli"
+	RedisLayerType    = "redis"
+
+	TelebotLongPoller    = "long"
+	TelebotWebhookPoller = "webhook"
+	// TelebotWebhookRoute is the route of the webhook when it is served by the http router
+	TelebotWebhookRoute = "/telebot"
+
+	GinURL          = "github.com/gin-gonic/gin"
+	EchoURL         = "github.com/labstack/echo/v4"
+	FiberURL        = "github.com/gofiber/fiber/v2"
+	FiberAdaptorURL = "github.com/gofiber/fiber/v2/middleware/adaptor"
+	GRPCURL         = "google.golang.org/grpc"
+
+	GraphQLURL      = "github.com/graph-gophers/graphql-go"
+	GraphQLRelayURL = "github.com/graph-gophers/graphql-go/relay"
 
 	GormURL               = "gorm.io/gorm"
 	GormPostgresDriverURL = "gorm.io/driver/postgres"
-	TelebotURL            = "github.com/tucnak/telebot"
+	GormMySQLDriverURL    = "gorm.io/driver/mysql"
+	GormSQLiteDriverURL   = "gorm.io/driver/sqlite"
+	TelebotURL            = "gopkg.in/telebot.v3"
+	DiscordURL            = "github.com/bwmarrin/discordgo"
+	SlackURL              = "github.com/slack-go/slack"
+	SlackSocketModeURL    = "github.com/slack-go/slack/socketmode"
+	NatsURL               = "github.com/nats-io/nats.go"
+	RabbitMQURL           = "github.com/rabbitmq/amqp091-go"
+	CobraURL              = "github.com/spf13/cobra"
+	RedisURL              = "github.com/redis/go-redis/v9"
+	YamlURL               = "gopkg.in/yaml.v3"
+	TomlURL               = "github.com/pelletier/go-toml/v2"
+	MiniredisURL          = "github.com/alicebob/miniredis/v2"
+
+	MongoURL        = "go.mongodb.org/mongo-driver/v2/mongo"
+	MongoOptionsURL = "go.mongodb.org/mongo-driver/v2/mongo/options"
+	MongoBsonURL    = "go.mongodb.org/mongo-driver/v2/bson"
 )
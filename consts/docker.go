@@ -1,9 +1,13 @@
 package consts
 
-const DefaultPostgresDockerfile = `version: '3.8'
+const DockerComposeHeader = `version: '3.8'
 
 services:
-  postgres:
+`
+
+const (
+	DefaultPostgresDockerVolume  = "postgres_data"
+	DefaultPostgresDockerService = `  postgres:
     image: postgres:latest
     container_name: postgres
     environment:
@@ -14,7 +18,48 @@ services:
       - "5432:5432"
     volumes:
       - postgres_data:/var/lib/postgresql/data
+`
 
-volumes:
-  postgres_data:
+	DefaultMySQLDockerVolume  = "mysql_data"
+	DefaultMySQLDockerService = `  mysql:
+    image: mysql:latest
+    container_name: mysql
+    environment:
+      MYSQL_DATABASE: default
+      MYSQL_USER: mysql
+      MYSQL_PASSWORD: mysql
+      MYSQL_ROOT_PASSWORD: mysql
+    ports:
+      - "3306:3306"
+    volumes:
+      - mysql_data:/var/lib/mysql
+`
+
+	DefaultMongoDockerVolume  = "mongo_data"
+	DefaultMongoDockerService = `  mongodb:
+    image: mongo:latest
+    container_name: mongodb
+    ports:
+      - "27017:27017"
+    volumes:
+      - mongo_data:/data/db
+`
+
+	DefaultRedisDockerVolume  = "redis_data"
+	DefaultRedisDockerService = `  redis:
+    image: redis:latest
+    container_name: redis
+    ports:
+      - "6379:6379"
+    volumes:
+      - redis_data:/data
+`
+
+	DefaultNatsDockerService = `  nats:
+    image: nats:latest
+    container_name: nats
+    command: ["-js"]
+    ports:
+      - "4222:4222"
 `
+)
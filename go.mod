@@ -2,7 +2,10 @@ module gowizard
 
 go 1.22.0
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	github.com/pelletier/go-toml/v2 v2.2.4
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/cpuguy83/go-md2man/v2 v2.0.4 // indirect
@@ -19,6 +19,15 @@ func Run(args []string) {
 	case g, gen, generate:
 		response = handleCommand(args, commands.NewGenerateCommand)
 
+	case initialize:
+		response = handleCommand(args, commands.NewInitCommand)
+
+	case validate:
+		response = handleCommand(args, commands.NewValidateCommand)
+
+	case add:
+		response = handleCommand(args, commands.NewAddCommand)
+
 	case help:
 		responses.PrintHelp(responses.Help)
 		return
@@ -41,6 +50,15 @@ const (
 	g        = "g"
 	gen      = "gen"
 	generate = "generate"
+
+	// Init command
+	initialize = "init"
+
+	// Validate command
+	validate = "validate"
+
+	// Add command
+	add = "add"
 )
 
 func handleCommand(args []string, commandGet func(args []string) (commands.Command, error)) string {
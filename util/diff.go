@@ -0,0 +1,240 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the count of unchanged lines around each change of a hunk
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// UnifiedDiff writes the changes from a to b in the unified format, empty when they are equal
+func UnifiedDiff(name string, a, b []byte) string {
+	ops := diffLines(splitLines(string(a)), splitLines(string(b)))
+
+	// aPos and bPos are the count of lines of a and b before each op
+	aPos := make([]int, len(ops)+1)
+	bPos := make([]int, len(ops)+1)
+	for i, op := range ops {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		if op.kind != '+' {
+			aPos[i+1]++
+		}
+		if op.kind != '-' {
+			bPos[i+1]++
+		}
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(ops); {
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+
+		// the hunk grows while the next change is close enough to share the context
+		start, end := max(i-diffContext, 0), i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				end = min(end+diffContext, len(ops))
+				break
+			}
+			end = next
+		}
+
+		if sb.Len() == 0 {
+			sb.WriteString(fmt.Sprintf("--- a/%s\n+++ b/%s\n", name, name))
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(aPos[start], aPos[end]-aPos[start]), hunkRange(bPos[start], bPos[end]-bPos[start])))
+		for _, op := range ops[start:end] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		i = end
+	}
+
+	return sb.String()
+}
+
+// hunkRange is the start line and the count of lines of a hunk side, an empty side starts before its first line
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// splitLines splits the text after each new line, so the last line keeps the lack of one
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines builds the edit script from a to b with the longest common subsequence of the lines
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i][j] is the longest common subsequence of midA[i:] and midB[j:]
+	lcs := make([][]int, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			ops = append(ops, diffOp{' ', midA[i]})
+			i++
+			j++
+		case j == len(midB) || (i < len(midA) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', midA[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', midB[j]})
+			j++
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	return ops
+}
+
+// mergeEdit replaces count lines of ours from at with lines
+type mergeEdit struct {
+	at, count int
+	lines     []string
+}
+
+// Merge3 applies the changes from base to theirs onto ours, so the edits of ours away from the
+// changes stay, it fails when ours edited the lines a change touches
+func Merge3(base, ours, theirs []byte) ([]byte, error) {
+	b, o, t := splitLines(string(base)), splitLines(string(ours)), splitLines(string(theirs))
+
+	// kept maps the lines of base to their place in ours, -1 when ours edited them
+	kept := make([]int, len(b))
+	i, j := 0, 0
+	for _, op := range diffLines(b, o) {
+		switch op.kind {
+		case ' ':
+			kept[i] = j
+			i++
+			j++
+		case '-':
+			kept[i] = -1
+			i++
+		case '+':
+			j++
+		}
+	}
+
+	var edits []mergeEdit
+	ops := diffLines(b, t)
+	for k, i := 0, 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			i++
+			continue
+		}
+
+		start := i
+		var lines []string
+		for ; k < len(ops) && ops[k].kind != ' '; k++ {
+			if ops[k].kind == '-' {
+				i++
+			} else {
+				lines = append(lines, ops[k].line)
+			}
+		}
+
+		edit := mergeEdit{count: i - start, lines: lines}
+		switch {
+		case start < i:
+			// the replaced lines must be untouched and still together in ours
+			edit.at = kept[start]
+			for l := start; l < i; l++ {
+				if kept[l] == -1 || kept[l] != edit.at+l-start {
+					return nil, fmt.Errorf("line %d of the base is edited on both sides", l+1)
+				}
+			}
+		case start > 0 && kept[start-1] != -1:
+			edit.at = kept[start-1] + 1
+		case start < len(b) && kept[start] != -1:
+			edit.at = kept[start]
+		case start == 0:
+			edit.at = 0
+		default:
+			return nil, fmt.Errorf("the lines around line %d of the base are edited", start+1)
+		}
+		edits = append(edits, edit)
+	}
+
+	var sb strings.Builder
+	at := 0
+	for _, edit := range edits {
+		if edit.at < at {
+			return nil, fmt.Errorf("line %d of ours is edited on both sides", edit.at+1)
+		}
+		sb.WriteString(strings.Join(o[at:edit.at], ""))
+		sb.WriteString(strings.Join(edit.lines, ""))
+		at = edit.at + edit.count
+	}
+	sb.WriteString(strings.Join(o[at:], ""))
+
+	return []byte(sb.String()), nil
+}
@@ -0,0 +1,159 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	// numbered is the lines from 1 to n, each replaced by the one in changes
+	numbered := func(n int, changes map[int]string) string {
+		var sb strings.Builder
+		for i := 1; i <= n; i++ {
+			line, ok := changes[i]
+			if !ok {
+				line = fmt.Sprint(i)
+			}
+			sb.WriteString(line + "\n")
+		}
+		return sb.String()
+	}
+
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "changed line",
+			a:    numbered(10, nil),
+			b:    numbered(10, map[int]string{5: "five"}),
+			want: "--- a/service/user.go\n+++ b/service/user.go\n" +
+				"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "close changes share a hunk",
+			a:    numbered(12, nil),
+			b:    numbered(12, map[int]string{3: "three", 8: "eight"}),
+			want: "--- a/service/user.go\n+++ b/service/user.go\n" +
+				"@@ -1,11 +1,11 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n 7\n-8\n+eight\n 9\n 10\n 11\n",
+		},
+		{
+			name: "far changes split hunks",
+			a:    numbered(20, nil),
+			b:    numbered(20, map[int]string{2: "two", 18: "eighteen"}),
+			want: "--- a/service/user.go\n+++ b/service/user.go\n" +
+				"@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n" +
+				"@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+eighteen\n 19\n 20\n",
+		},
+		{
+			name: "new file",
+			a:    "",
+			b:    "a\n",
+			want: "--- a/service/user.go\n+++ b/service/user.go\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			name: "no newline at end of file",
+			a:    "a\nb",
+			b:    "a\nb\n",
+			want: "--- a/service/user.go\n+++ b/service/user.go\n" +
+				"@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("service/user.go", []byte(tt.a), []byte(tt.b))
+			if got != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	base := "package service\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\treturn\n}\n"
+
+	tests := []struct {
+		name    string
+		ours    string
+		theirs  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "unchanged",
+			ours:   base,
+			theirs: base,
+			want:   base,
+		},
+		{
+			name:   "only theirs",
+			ours:   base,
+			theirs: strings.Replace(base, "func b()", "func c()", 1),
+			want:   strings.Replace(base, "func b()", "func c()", 1),
+		},
+		{
+			name:   "only ours",
+			ours:   strings.Replace(base, "func a()", "func z()", 1),
+			theirs: base,
+			want:   strings.Replace(base, "func a()", "func z()", 1),
+		},
+		{
+			name:   "both away from each other",
+			ours:   strings.Replace(base, "func a()", "func z()", 1),
+			theirs: strings.Replace(base, "func b()", "func c()", 1),
+			want:   strings.Replace(strings.Replace(base, "func a()", "func z()", 1), "func b()", "func c()", 1),
+		},
+		{
+			name:   "inserts",
+			ours:   base + "\nfunc mine() {}\n",
+			theirs: strings.Replace(base, "package service\n", "package service\n\nimport \"errors\"\n", 1),
+			want:   strings.Replace(base, "package service\n", "package service\n\nimport \"errors\"\n", 1) + "\nfunc mine() {}\n",
+		},
+		{
+			name:    "same line",
+			ours:    strings.Replace(base, "func b()", "func mine()", 1),
+			theirs:  strings.Replace(base, "func b()", "func c()", 1),
+			wantErr: "line 7 of the base is edited on both sides",
+		},
+		{
+			name:   "insert next to an edit",
+			ours:   strings.Replace(base, "}\n\nfunc b", "}\n// mine\nfunc b", 1),
+			theirs: strings.Replace(base, "}\n\nfunc b", "}\n\nvar x int\nfunc b", 1),
+			want:   strings.Replace(base, "}\n\nfunc b", "}\n// mine\nvar x int\nfunc b", 1),
+		},
+		{
+			name:    "insert between edits",
+			ours:    strings.Replace(base, "}\n\nfunc b()", "}\n// mine\nfunc mine()", 1),
+			theirs:  strings.Replace(base, "}\n\nfunc b", "}\n\nvar x int\nfunc b", 1),
+			wantErr: "the lines around line 7 of the base are edited",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Merge3([]byte(base), []byte(tt.ours), []byte(tt.theirs))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}